@@ -0,0 +1,164 @@
+package gojson
+
+import (
+	"errors"
+	"fmt"
+)
+
+// collectNumbers 通过ToFloat64取出数组中的数字，useKey为true时取元素中key对应的值。
+// strict为false时跳过缺少或无法转换的值，为true时返回error
+func (j *GoJson) collectNumbers(key string, useKey bool, strict bool) ([]float64, error) {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	l, ok := toSlice(j.data)
+	if !ok {
+		return nil, fmt.Errorf("%v is not slice", j.data)
+	}
+	result := make([]float64, 0, len(l))
+	for i, item := range l {
+		val := item
+		if useKey {
+			if val, ok = lookupMap(key, item); !ok {
+				if strict {
+					return nil, fmt.Errorf("element %d: key %s not found", i, key)
+				}
+				continue
+			}
+		}
+		f, err := ToFloat64(val)
+		if err != nil {
+			if strict {
+				return nil, fmt.Errorf("element %d: %v", i, err)
+			}
+			continue
+		}
+		result = append(result, f)
+	}
+	return result, nil
+}
+
+// Numbers 返回数组中的所有数字，元素通过ToFloat64转换。strict为false时跳过无法转换的元素，为true时返回error
+func (j *GoJson) Numbers(strict bool) ([]float64, error) {
+	return j.collectNumbers("", false, strict)
+}
+
+// NumbersBy 返回元素为k-v结构的数组中key对应的所有数字，值通过ToFloat64转换。
+// strict为false时跳过缺少该key或无法转换的元素，为true时返回error
+func (j *GoJson) NumbersBy(key string, strict bool) ([]float64, error) {
+	return j.collectNumbers(key, true, strict)
+}
+
+func sumFloat64(nums []float64) float64 {
+	sum := 0.0
+	for _, n := range nums {
+		sum += n
+	}
+	return sum
+}
+
+func minFloat64(nums []float64) (float64, error) {
+	if len(nums) == 0 {
+		return 0, errors.New("no numbers found")
+	}
+	min := nums[0]
+	for _, n := range nums[1:] {
+		if n < min {
+			min = n
+		}
+	}
+	return min, nil
+}
+
+func maxFloat64(nums []float64) (float64, error) {
+	if len(nums) == 0 {
+		return 0, errors.New("no numbers found")
+	}
+	max := nums[0]
+	for _, n := range nums[1:] {
+		if n > max {
+			max = n
+		}
+	}
+	return max, nil
+}
+
+func avgFloat64(nums []float64) (float64, error) {
+	if len(nums) == 0 {
+		return 0, errors.New("no numbers found")
+	}
+	return sumFloat64(nums) / float64(len(nums)), nil
+}
+
+// Sum 返回数组中所有数字的和，跳过无法转换为数字的元素，源数据不是数组时返回error
+func (j *GoJson) Sum() (float64, error) {
+	nums, err := j.Numbers(false)
+	if err != nil {
+		return 0, err
+	}
+	return sumFloat64(nums), nil
+}
+
+// Min 返回数组中最小的数字，跳过无法转换为数字的元素，没有数字或源数据不是数组时返回error
+func (j *GoJson) Min() (float64, error) {
+	nums, err := j.Numbers(false)
+	if err != nil {
+		return 0, err
+	}
+	return minFloat64(nums)
+}
+
+// Max 返回数组中最大的数字，跳过无法转换为数字的元素，没有数字或源数据不是数组时返回error
+func (j *GoJson) Max() (float64, error) {
+	nums, err := j.Numbers(false)
+	if err != nil {
+		return 0, err
+	}
+	return maxFloat64(nums)
+}
+
+// Avg 返回数组中数字的平均值，跳过无法转换为数字的元素，没有数字或源数据不是数组时返回error
+func (j *GoJson) Avg() (float64, error) {
+	nums, err := j.Numbers(false)
+	if err != nil {
+		return 0, err
+	}
+	return avgFloat64(nums)
+}
+
+// SumBy 返回元素为k-v结构的数组中key对应的数字之和，跳过缺少该key或无法转换的元素，源数据不是数组时返回error
+func (j *GoJson) SumBy(key string) (float64, error) {
+	nums, err := j.NumbersBy(key, false)
+	if err != nil {
+		return 0, err
+	}
+	return sumFloat64(nums), nil
+}
+
+// MinBy 返回元素为k-v结构的数组中key对应的最小数字，跳过缺少该key或无法转换的元素，没有数字时返回error
+func (j *GoJson) MinBy(key string) (float64, error) {
+	nums, err := j.NumbersBy(key, false)
+	if err != nil {
+		return 0, err
+	}
+	return minFloat64(nums)
+}
+
+// MaxBy 返回元素为k-v结构的数组中key对应的最大数字，跳过缺少该key或无法转换的元素，没有数字时返回error
+func (j *GoJson) MaxBy(key string) (float64, error) {
+	nums, err := j.NumbersBy(key, false)
+	if err != nil {
+		return 0, err
+	}
+	return maxFloat64(nums)
+}
+
+// AvgBy 返回元素为k-v结构的数组中key对应的数字的平均值，跳过缺少该key或无法转换的元素，没有数字时返回error
+func (j *GoJson) AvgBy(key string) (float64, error) {
+	nums, err := j.NumbersBy(key, false)
+	if err != nil {
+		return 0, err
+	}
+	return avgFloat64(nums)
+}
@@ -0,0 +1,104 @@
+package gojson
+
+import (
+	"strconv"
+	"testing"
+)
+
+var benchSink *GoJson
+
+// flatObject 返回10个key的扁平k-v结构，值都是标量
+func flatObject() *GoJson {
+	j := NewObject()
+	for i := 0; i < 10; i++ {
+		j.Set("key"+strconv.Itoa(i), "value"+strconv.Itoa(i))
+	}
+	return j
+}
+
+// BenchmarkFlatGet 10个key的扁平k-v结构上的Get。与旧的实现对比时，在旧版本的代码上运行同一个benchmark
+func BenchmarkFlatGet(b *testing.B) {
+	j := flatObject()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchSink = j.Get("key5")
+	}
+}
+
+func BenchmarkFlatSet(b *testing.B) {
+	j := flatObject()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		j.Set("key5", i)
+	}
+}
+
+// BenchmarkGetNoPool 与BenchmarkGetPool对比，不开启EnablePool时每次Get都会分配节点
+func BenchmarkGetNoPool(b *testing.B) {
+	j := NewJsonFromString(`{"data":{"user":{"name":"gojson"}}}`)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		data := j.Get("data")
+		user := data.Get("user")
+		user.Get("name").Release()
+		user.Release()
+		data.Release()
+	}
+}
+
+func BenchmarkGetPool(b *testing.B) {
+	EnablePool = true
+	defer func() { EnablePool = false }()
+	j := NewJsonFromString(`{"data":{"user":{"name":"gojson"}}}`)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		data := j.Get("data")
+		user := data.Get("user")
+		user.Get("name").Release()
+		user.Release()
+		data.Release()
+	}
+}
+
+// benchRecord 用于输出的典型记录
+func benchRecord() *GoJson {
+	return NewJsonFromString(`{"id":12345,"name":"gojson","tags":["a","b","c"],"price":9.99,"ok":true}`)
+}
+
+// BenchmarkBytesLoop 与BenchmarkAppendBytesLoop对比，每次Bytes都会分配新的结果
+func BenchmarkBytesLoop(b *testing.B) {
+	j := benchRecord()
+	var out []byte
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		out = append(out[:0], j.Bytes()...)
+	}
+}
+
+func BenchmarkAppendBytesLoop(b *testing.B) {
+	j := benchRecord()
+	var out []byte
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var err error
+		if out, err = j.AppendBytes(out[:0]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkGetMiss 查找失败时只记录失败的节点，调用Err时才生成error
+func BenchmarkGetMiss(b *testing.B) {
+	j := flatObject()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchSink = j.Get("missing")
+	}
+}
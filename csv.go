@@ -0,0 +1,79 @@
+package gojson
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// csvCell 将值转换为csv中的一格：null为空，map和数组编码为json，其余通过ToString转换
+func csvCell(val interface{}) (string, error) {
+	switch val.(type) {
+	case nil:
+		return "", nil
+	case map[string]interface{}, Dict, *OrderedDict, []interface{}, List:
+		b, err := marshal(val)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	default:
+		return ToString(val), nil
+	}
+}
+
+// ToCSV 将元素为k-v结构的数组写为csv，第一行为表头，之后每个元素一行。
+// columns为空时使用所有元素的key的并集，按字典序排列。缺少的key和null为空，嵌套的map和数组编码为json。
+// 当json不为slice或元素不是k-v结构时返回error
+func (j *GoJson) ToCSV(w io.Writer, columns ...string) error {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	l, ok := toSlice(j.data)
+	if !ok {
+		return fmt.Errorf("%v is not slice", j.data)
+	}
+	rows := make([]map[string]interface{}, 0, len(l))
+	for i, item := range l {
+		m, ok := toMap(item)
+		if !ok {
+			return fmt.Errorf("element %d: %v is not map", i, item)
+		}
+		rows = append(rows, m)
+	}
+
+	if len(columns) == 0 {
+		seen := make(map[string]bool)
+		for _, row := range rows {
+			for key := range row {
+				if !seen[key] {
+					seen[key] = true
+					columns = append(columns, key)
+				}
+			}
+		}
+		sort.Strings(columns)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+	record := make([]string, len(columns))
+	for _, row := range rows {
+		for i, column := range columns {
+			cell, err := csvCell(row[column])
+			if err != nil {
+				return err
+			}
+			record[i] = cell
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,81 @@
+package gojson
+
+import (
+	"sort"
+	"strconv"
+)
+
+func joinPath(path, seg string) string {
+	if path == "" {
+		return seg
+	}
+	return path + "." + seg
+}
+
+func diffValue(path string, a, b interface{}, result []interface{}) []interface{} {
+	aMap, aIsMap := toMap(a)
+	bMap, bIsMap := toMap(b)
+	if aIsMap && bIsMap {
+		keys := make([]string, 0, len(aMap)+len(bMap))
+		for key := range aMap {
+			keys = append(keys, key)
+		}
+		for key := range bMap {
+			if _, ok := aMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			childPath := joinPath(path, escapeKey(key, "."))
+			aVal, aOk := aMap[key]
+			bVal, bOk := bMap[key]
+			switch {
+			case !bOk:
+				result = append(result, map[string]interface{}{"op": "remove", "path": childPath, "old": aVal})
+			case !aOk:
+				result = append(result, map[string]interface{}{"op": "add", "path": childPath, "new": bVal})
+			default:
+				result = diffValue(childPath, aVal, bVal, result)
+			}
+		}
+		return result
+	}
+
+	aSlice, aIsSlice := toSlice(a)
+	bSlice, bIsSlice := toSlice(b)
+	if aIsSlice && bIsSlice {
+		for i := 0; i < len(aSlice) || i < len(bSlice); i++ {
+			childPath := joinPath(path, strconv.Itoa(i))
+			switch {
+			case i >= len(bSlice):
+				result = append(result, map[string]interface{}{"op": "remove", "path": childPath, "old": aSlice[i]})
+			case i >= len(aSlice):
+				result = append(result, map[string]interface{}{"op": "add", "path": childPath, "new": bSlice[i]})
+			default:
+				result = diffValue(childPath, aSlice[i], bSlice[i], result)
+			}
+		}
+		return result
+	}
+
+	if !equalValue(a, b) {
+		result = append(result, map[string]interface{}{"op": "replace", "path": path, "old": a, "new": b})
+	}
+	return result
+}
+
+// Diff 比较当前对象与other，返回描述差异的数组，每个元素为 {"op":..., "path":..., "old":..., "new":...}。
+// op为add、remove或replace，path为GetPath格式的路径。数字比较规则与Equals相同
+func (j *GoJson) Diff(other *GoJson) *GoJson {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	var otherData interface{}
+	if other != nil {
+		otherData = other.data
+	}
+	return NewJsonFromData(diffValue("", j.data, otherData, make([]interface{}, 0)))
+}
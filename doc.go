@@ -0,0 +1,13 @@
+// Package gojson 对json数据的封装，用interface{}屏蔽了对结构体的使用依赖。
+//
+// 并发：通过Get、Index得到的子节点与父节点共享底层数据，同一棵树上的所有节点共用根节点的读写锁。
+// 不带回调的读方法(Get、GetPath、String、Int、Kind等)加读锁，写方法(Set、Append、Remove、Merge等)加写锁，
+// 每次调用是原子的，因此可以在多个goroutine中通过这些方法同时读写同一棵树。
+//
+// 带回调的方法(RangeMap、RangeSlice、Filter、MapSlice、FindIndex、Walk、RangeDeep、SelectAll等)在读锁内复制
+// 要遍历的元素，之后在锁外调用回调，回调中可以调用同一棵树上的方法。Sort、Update在锁外对副本调用回调，
+// 再在写锁内写回，期间数组长度被其他goroutine改变时放弃写回。
+//
+// 回调收到的值以及Value、Array等返回的map和数组与树共享数据，之后直接读写它们不受锁保护，
+// 与其他goroutine的写操作并发时需要改用GoJson的方法或先Clone。作为参数传入的其他GoJson对象不会被加锁
+package gojson
@@ -0,0 +1,171 @@
+package gojson
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Encoder 将GoJson逐个节点编码写入io.Writer，不需要先在内存中生成完整的json。
+// 通过GoJson的NewEncoder创建，Encode输出整个对象；当前对象为数组时，也可以通过Append逐个写出新元素，最后调用Close结束数组
+type Encoder struct {
+	j         *GoJson
+	w         *bufio.Writer
+	streaming bool // 是否已通过Append开始输出数组
+	closed    bool
+	err       error
+}
+
+// NewEncoder 创建将当前对象写入w的Encoder。map的key按字典序输出(OrderedDict按插入顺序)，数字按原样输出，是否转义HTML字符与EscapeHTML的设置一致
+func (j *GoJson) NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{j: j, w: bufio.NewWriter(w)}
+}
+
+// writeStream 将val编码写入w，map和数组逐个元素写出，其余值(包括值为nil的map和数组)使用marshal编码
+func writeStream(w *bufio.Writer, val interface{}) error {
+	if m, ok := toMap(val); ok && m != nil {
+		var keys []string
+		if d, ok := val.(*OrderedDict); ok {
+			keys = d.Keys()
+		} else {
+			keys = make([]string, 0, len(m))
+			for key := range m {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+		}
+
+		w.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			keyBytes, err := marshal(key)
+			if err != nil {
+				return err
+			}
+			w.Write(keyBytes)
+			w.WriteByte(':')
+			if err := writeStream(w, m[key]); err != nil {
+				return err
+			}
+		}
+		w.WriteByte('}')
+		return nil
+	}
+
+	if l, ok := toSlice(val); ok && l != nil {
+		w.WriteByte('[')
+		for i, item := range l {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			if err := writeStream(w, item); err != nil {
+				return err
+			}
+		}
+		w.WriteByte(']')
+		return nil
+	}
+
+	result, err := marshal(val)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(result)
+	return err
+}
+
+// Encode 将整个对象编码写入，末尾带换行符，与WriteTo的输出相同。已调用Append后不能再调用Encode
+func (e *Encoder) Encode() error {
+	if e.err != nil {
+		return e.err
+	}
+	if e.streaming || e.closed {
+		return errors.New("encoder is streaming or closed cannot encode")
+	}
+
+	r := e.j.root()
+	r.RLock()
+	err := writeStream(e.w, e.j.data)
+	r.RUnlock()
+	if err != nil {
+		e.err = err
+		return err
+	}
+	e.w.WriteByte('\n')
+	e.err = e.w.Flush()
+	return e.err
+}
+
+// Append 将val作为数组的下一个元素立即写出，不会保存到当前对象中。val为*GoJson时使用其数据。
+// 第一次调用时先写出 [ 和当前对象中已有的元素，当前对象不为slice时返回error
+func (e *Encoder) Append(val interface{}) error {
+	if e.err != nil {
+		return e.err
+	}
+	if e.closed {
+		return errors.New("encoder is closed cannot append")
+	}
+	if value, ok := val.(*GoJson); ok {
+		val = value.Value()
+	}
+
+	needComma := e.streaming
+	if !e.streaming {
+		r := e.j.root()
+		r.RLock()
+		l, ok := toSlice(e.j.data)
+		if !ok {
+			r.RUnlock()
+			return fmt.Errorf("%v is not slice cannot append", e.j.data)
+		}
+		e.w.WriteByte('[')
+		for i, item := range l {
+			if i > 0 {
+				e.w.WriteByte(',')
+			}
+			if err := writeStream(e.w, item); err != nil {
+				r.RUnlock()
+				e.err = err
+				return err
+			}
+		}
+		r.RUnlock()
+		e.streaming = true
+		needComma = len(l) > 0
+	}
+	if needComma {
+		e.w.WriteByte(',')
+	}
+
+	if err := writeStream(e.w, val); err != nil {
+		e.err = err
+		return err
+	}
+	return nil
+}
+
+// Flush 将缓冲中的数据写入底层的io.Writer
+func (e *Encoder) Flush() error {
+	if e.err != nil {
+		return e.err
+	}
+	e.err = e.w.Flush()
+	return e.err
+}
+
+// Close 结束通过Append输出的数组并写入底层的io.Writer，末尾带换行符。未调用过Append时什么都不会写出
+func (e *Encoder) Close() error {
+	if e.err != nil || e.closed {
+		return e.err
+	}
+	e.closed = true
+	if !e.streaming {
+		return nil
+	}
+	e.w.WriteString("]\n")
+	return e.Flush()
+}
@@ -0,0 +1,101 @@
+package gojson
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncoderEncode(t *testing.T) {
+	values := []interface{}{
+		`{"b":[1,2.50,{"d":null}],"a":"<x>","c":{}}`,
+		`[]`,
+		`"s"`,
+		map[string]interface{}{"nil": map[string]interface{}(nil), "l": []interface{}(nil), "d": Dict{"k": List{1}}},
+	}
+	for _, v := range values {
+		var j *GoJson
+		if s, ok := v.(string); ok {
+			j = NewJsonFromString(s)
+		} else {
+			j = NewJsonFromData(v)
+		}
+		var want, got bytes.Buffer
+		if _, err := j.WriteTo(&want); err != nil {
+			t.Fatal(err)
+		}
+		if err := j.NewEncoder(&got).Encode(); err != nil {
+			t.Fatal(err)
+		}
+		if got.String() != want.String() {
+			t.Errorf("Encode = %q, WriteTo = %q", got.String(), want.String())
+		}
+	}
+
+	ordered, _ := NewJsonFromBytesWithOptions([]byte(`{"z":1,"a":{"y":2,"b":3}}`), Options{PreserveOrder: true})
+	var buffer bytes.Buffer
+	if err := ordered.NewEncoder(&buffer).Encode(); err != nil || buffer.String() != "{\"z\":1,\"a\":{\"y\":2,\"b\":3}}\n" {
+		t.Errorf("OrderedDict: got %q, %v", buffer.String(), err)
+	}
+}
+
+func TestEncoderAppend(t *testing.T) {
+	var buffer bytes.Buffer
+	e := NewJsonFromString(`[1,{"a":"x"}]`).NewEncoder(&buffer)
+	if err := e.Append(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	// Flush后已写出的部分不含结尾的 ]
+	if got := buffer.String(); got != `[1,{"a":"x"},2` {
+		t.Errorf("after Flush: %q", got)
+	}
+	if err := e.Append(NewJsonFromString(`{"b":[true]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Encode(); err == nil {
+		t.Error("Encode after Append should fail")
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := buffer.String(); got != "[1,{\"a\":\"x\"},2,{\"b\":[true]}]\n" {
+		t.Errorf("got %q", got)
+	}
+	if err := e.Append(3); err == nil {
+		t.Error("Append after Close should fail")
+	}
+	if err := e.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+}
+
+func TestEncoderAppendFraming(t *testing.T) {
+	// 空数组开始时第一个元素前没有逗号
+	var buffer bytes.Buffer
+	e := NewJsonFromString(`[]`).NewEncoder(&buffer)
+	e.Append("a")
+	e.Append(nil)
+	e.Close()
+	if got := buffer.String(); got != "[\"a\",null]\n" {
+		t.Errorf("got %q", got)
+	}
+	if !NewJsonFromString(buffer.String()).Equals(NewJsonFromData([]interface{}{"a", nil})) {
+		t.Error("streamed output does not parse back")
+	}
+
+	// 未调用Append时Close什么都不写出
+	buffer.Reset()
+	if err := NewJsonFromString(`[1]`).NewEncoder(&buffer).Close(); err != nil || buffer.Len() != 0 {
+		t.Errorf("Close without Append wrote %q, %v", buffer.String(), err)
+	}
+
+	buffer.Reset()
+	if err := NewJsonFromString(`{"a":1}`).NewEncoder(&buffer).Append(1); err == nil {
+		t.Error("Append on a map should fail")
+	}
+	if buffer.Len() != 0 {
+		t.Errorf("failed Append wrote %q", buffer.String())
+	}
+}
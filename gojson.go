@@ -21,20 +21,47 @@ package gojson
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	sysjson "encoding/json"
 	"errors"
 	"fmt"
 	jsoniterator "github.com/json-iterator/go"
 	"io"
+	"io/ioutil"
 	"log"
 	"math"
+	"math/big"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 )
 
 var json = jsoniterator.ConfigCompatibleWithStandardLibrary
 
+// jsonNoEscapeHTML 与json相同，但不转义HTML字符
+var jsonNoEscapeHTML = jsoniterator.Config{
+	EscapeHTML:             false,
+	SortMapKeys:            true,
+	ValidateJsonRawMessage: true,
+}.Froze()
+
+// EscapeHTML 为false时，String、Bytes等输出不再将 < > & 转义为 \u003c 等
+var EscapeHTML = true
+
+// marshal 按EscapeHTML的设置进行json编码
+func marshal(v interface{}) ([]byte, error) {
+	if EscapeHTML {
+		return json.Marshal(v)
+	}
+	return jsonNoEscapeHTML.Marshal(v)
+}
+
 var Debug = true
 
 func debugf(format string, v ...interface{}) {
@@ -43,14 +70,42 @@ func debugf(format string, v ...interface{}) {
 	}
 }
 
+// GoJson 对json数据的封装。
+// 通过Get、Index得到的子节点与父节点共享底层数据，因此同一棵树上的所有节点共用根节点的读写锁：
+// 读方法加读锁，写方法加写锁。带回调的方法(RangeMap、RangeSlice、Walk等)在锁外调用回调，回调中可以修改数据，
+// 回调收到的map和数组与树共享数据，不受锁保护。作为参数传入的其他GoJson对象不会被加锁，跨树操作时需调用方自行保证
 type GoJson struct {
 	prev      *GoJson
 	prevKey   string
 	prevIndex int
 	data      interface{}
+	exists    bool     // 通过Get、Index得到的节点，key或下标是否真实存在
+	failed    bool     // 通过Get、Index链式访问是否已经失败
+	navErr    navError // 第一次失败的信息，调用Err时才生成error
+	lastErr   error    // 创建时解析失败的原因
+	frozen    bool     // 是否已通过Freeze冻结，只在根节点上设置
 	sync.RWMutex
 }
 
+// navError 通过Get、Index链式访问失败时生成error需要的信息。按值保存并复制给后续节点，
+// 不引用其他节点，因此失败的节点或其父节点被Release后仍然可以调用Err
+type navError struct {
+	parent  string // 父节点的路径
+	key     string // 访问的key
+	index   int    // 访问的下标，保留调用方传入的负数下标
+	isIndex bool   // 访问的是下标还是key
+	reason  string // 失败的原因，空表示key或下标不存在，否则表示父节点的类型不符
+}
+
+// root 返回节点所在树的根节点，整棵树共用根节点的锁
+func (j *GoJson) root() *GoJson {
+	r := j
+	for r.prev != nil {
+		r = r.prev
+	}
+	return r
+}
+
 // NewJson 从string, []byte, interface{}等对象创建GoJson对象。从结构体创建应使用：NewJsonFromStruct
 func NewJson(data interface{}) *GoJson {
 	switch v := data.(type) {
@@ -63,9 +118,16 @@ func NewJson(data interface{}) *GoJson {
 	}
 }
 func decodeJson(r io.Reader) (*GoJson, error) {
+	return decodeJsonWith(r, true)
+}
+
+// decodeJsonWith 从r解析json，useNumber为false时数字解析为float64
+func decodeJsonWith(r io.Reader, useNumber bool) (*GoJson, error) {
 	var f interface{}
 	decoder := json.NewDecoder(r)
-	decoder.UseNumber() // UseNumber causes the Decoder to unmarshal a number into an interface{} as a Number instead of as a float64.
+	if useNumber {
+		decoder.UseNumber() // UseNumber causes the Decoder to unmarshal a number into an interface{} as a Number instead of as a float64.
+	}
 	if err := decoder.Decode(&f); err != nil {
 		return nil, err
 	}
@@ -73,26 +135,224 @@ func decodeJson(r io.Reader) (*GoJson, error) {
 	return &GoJson{data: f}, nil
 }
 
+// decodeJsonAll 与decodeJsonWith相同，但要求r中只有一个json值，之后除空白字符外还有其他数据时返回error
+func decodeJsonAll(r io.Reader, useNumber bool) (*GoJson, error) {
+	var f interface{}
+	decoder := json.NewDecoder(r)
+	if useNumber {
+		decoder.UseNumber()
+	}
+	if err := decoder.Decode(&f); err != nil {
+		return nil, err
+	}
+	if err := checkTrailing(decoder.Buffered(), r); err != nil {
+		return nil, err
+	}
+	return &GoJson{data: f}, nil
+}
+
+// checkTrailing 检查json值之后的数据(decoder中已缓冲的部分和r中剩余的部分)是否只有空白字符
+func checkTrailing(buffered io.Reader, r io.Reader) error {
+	rest, err := ioutil.ReadAll(io.MultiReader(buffered, r))
+	if err != nil {
+		return err
+	}
+	if s := strings.TrimLeft(string(rest), " \t\r\n"); s != "" {
+		return fmt.Errorf("invalid character %q after top-level value", s[0])
+	}
+	return nil
+}
+
+// parseFailed 返回解析失败时的空GoJson对象和error，error同时记录在对象上，可通过ParseError获取
+func parseFailed(err error) (*GoJson, error) {
+	err = fmt.Errorf("js解析失败：%v", err)
+	return &GoJson{lastErr: err}, err
+}
+
+// ParseError 返回创建当前对象时解析失败的原因，解析成功或不是通过解析创建时返回nil。
+// 用于检查NewJson、NewJsonFromBytes等不返回error的构造函数的结果
+func (j *GoJson) ParseError() error {
+	return j.lastErr
+}
+
 // NewJsonFromBytes 从bytes对象创建GoJson对象。bytes对象必须是标准的json格式。
+// 只解析第一个json值，之后多余的数据被忽略；需要检查多余数据时使用NewJsonFromBytesE
 func NewJsonFromBytes(b []byte) *GoJson {
 	js, err := decodeJson(bytes.NewReader(b))
 	if err != nil {
-		// errstr := fmt.Sprintf("js解析失败：%s", b)
-		return &GoJson{}
+		js, _ = parseFailed(err)
 	}
 	return js
 }
 
-// NewJsonFromString 从一个字符串对象创建GoJson对象
+// NewJsonFromBytesE 从bytes对象创建GoJson对象，解析失败时返回error。bytes为空或json之后还有多余的数据时同样返回error
+func NewJsonFromBytesE(b []byte) (*GoJson, error) {
+	js, err := decodeJsonAll(bytes.NewReader(b), true)
+	if err != nil {
+		return parseFailed(err)
+	}
+	return js, nil
+}
+
+// NewJsonFromString 从一个字符串对象创建GoJson对象。只解析第一个json值，之后多余的数据被忽略；需要检查多余数据时使用NewJsonFromStringE
 func NewJsonFromString(str string) *GoJson {
 	js, err := decodeJson(strings.NewReader(str))
 	if err != nil {
-		// errstr := fmt.Sprintf("js解析失败：%s", str)
-		return &GoJson{}
+		js, _ = parseFailed(err)
 	}
 	return js
 }
 
+// NewJsonFromStringE 从一个字符串对象创建GoJson对象，解析失败或json之后还有多余的数据时返回error
+func NewJsonFromStringE(str string) (*GoJson, error) {
+	js, err := decodeJsonAll(strings.NewReader(str), true)
+	if err != nil {
+		return parseFailed(err)
+	}
+	return js, nil
+}
+
+// NewJsonFromReader 从io.Reader创建GoJson对象，直接从流中解析，适用于http.Request.Body、文件等。
+// 只读取第一个json值，解析失败时返回error
+func NewJsonFromReader(r io.Reader) (*GoJson, error) {
+	js, err := decodeJson(r)
+	if err != nil {
+		return parseFailed(err)
+	}
+	return js, nil
+}
+
+// Options 解析json时的选项，零值表示默认行为
+type Options struct {
+	// MaxDepth 允许的最大嵌套深度，超过时解析失败，用于防止恶意输入导致栈溢出。0表示不限制
+	MaxDepth int
+	// StrictDuplicateKeys 为true时，任意k-v结构中出现重复的key都会导致解析失败，error中列出所有重复key的路径
+	StrictDuplicateKeys bool
+	// UseFloat64 为true时数字解析为float64而不是json.Number，比较和计算更简单，
+	// 但超过2^53的整数和高精度小数会丢失精度，重新输出时数字的写法也可能改变(如 1.50 变为 1.5)
+	UseFloat64 bool
+	// PreserveOrder 为true时k-v结构解析为OrderedDict，重新输出时保持原有的key顺序，用于对字段顺序敏感的场景(如签名)
+	PreserveOrder bool
+}
+
+// NewJsonFromBytesWithOptions 按opts从bytes对象创建GoJson对象，解析失败时返回error
+func NewJsonFromBytesWithOptions(b []byte, opts Options) (*GoJson, error) {
+	if opts.MaxDepth > 0 {
+		if err := checkDepth(b, opts.MaxDepth); err != nil {
+			return parseFailed(err)
+		}
+	}
+	if opts.StrictDuplicateKeys {
+		if err := checkDuplicateKeys(b); err != nil {
+			return parseFailed(err)
+		}
+	}
+	if opts.PreserveOrder {
+		reader := bytes.NewReader(b)
+		decoder := sysjson.NewDecoder(reader)
+		if !opts.UseFloat64 {
+			decoder.UseNumber()
+		}
+		data, err := decodeOrdered(decoder)
+		if err != nil {
+			return parseFailed(err)
+		}
+		if err := checkTrailing(decoder.Buffered(), reader); err != nil {
+			return parseFailed(err)
+		}
+		return NewJsonFromData(data), nil
+	}
+	js, err := decodeJsonAll(bytes.NewReader(b), !opts.UseFloat64)
+	if err != nil {
+		return parseFailed(err)
+	}
+	return js, nil
+}
+
+// checkDuplicateKeys 逐个token扫描，检查是否有k-v结构包含重复的key
+func checkDuplicateKeys(b []byte) error {
+	decoder := sysjson.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var duplicates []string
+	if err := scanDuplicateKeys(decoder, "", &duplicates); err != nil {
+		return err
+	}
+	if len(duplicates) > 0 {
+		return fmt.Errorf("duplicate keys: %s", strings.Join(duplicates, ", "))
+	}
+	return nil
+}
+
+// scanDuplicateKeys 读取一个完整的json值，将重复key的路径(GetPath格式)追加到duplicates
+func scanDuplicateKeys(decoder *sysjson.Decoder, path string, duplicates *[]string) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	switch token {
+	case sysjson.Delim('{'):
+		seen := make(map[string]bool)
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			key, _ := keyToken.(string)
+			childPath := joinPath(path, escapeKey(key, "."))
+			if seen[key] {
+				*duplicates = append(*duplicates, childPath)
+			}
+			seen[key] = true
+			if err := scanDuplicateKeys(decoder, childPath, duplicates); err != nil {
+				return err
+			}
+		}
+		_, err = decoder.Token()
+		return err
+	case sysjson.Delim('['):
+		for i := 0; decoder.More(); i++ {
+			if err := scanDuplicateKeys(decoder, joinPath(path, strconv.Itoa(i)), duplicates); err != nil {
+				return err
+			}
+		}
+		_, err = decoder.Token()
+		return err
+	}
+	return nil
+}
+
+// checkDepth 不解析数据，只扫描括号检查嵌套深度是否超过maxDepth
+func checkDepth(b []byte, maxDepth int) error {
+	depth := 0
+	inString := false
+	escaped := false
+	for i, c := range b {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if depth > maxDepth {
+				return fmt.Errorf("exceeded max depth %d at offset %d", maxDepth, i)
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+	return nil
+}
+
 func NewErrJson(errcode int, errmsg string) *GoJson {
 	result := NewJsonFromString("{}")
 	result.Set("err_msg", errmsg)
@@ -101,62 +361,395 @@ func NewErrJson(errcode int, errmsg string) *GoJson {
 	return result
 }
 
-// NewJsonFromStruct 从一个结构体对象创建GoJson对象
+// NewJsonFromStruct 从一个结构体对象创建GoJson对象，编码失败时返回的对象 IsNil将为true，原因可通过ParseError获取
 func NewJsonFromStruct(b interface{}) *GoJson {
 	var f interface{}
 	bytesArr, err := json.Marshal(b)
 	if err != nil {
-		// errstr := fmt.Sprintf("js解析失败：%v", err)
-		return &GoJson{}
+		js, _ := parseFailed(err)
+		return js
 	}
 
 	err = json.Unmarshal(bytesArr, &f)
 	if err != nil {
-		// errstr := fmt.Sprintf("js解析失败：%v", err)
-		return &GoJson{}
+		js, _ := parseFailed(err)
+		return js
 	}
 
 	return &GoJson{data: f}
 }
 
+// To 将GoJson对象的数据绑定到target结构体中，target须为指针，支持json tag。与NewJsonFromStruct方向相反
+func (j *GoJson) To(target interface{}) error {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	if j.data == nil {
+		return errors.New("json data is nil, cannot bind to target")
+	}
+	bytesArr, err := json.Marshal(j.data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bytesArr, target)
+}
+
+// Extract 按mapping从json中取值并绑定到target结构体中，target须为指针。mapping的key为target中"."分隔的字段路径
+// (字段名或json tag，如 User.Name)，value为GetPath格式的json路径，如 {"User.Name": "data.user.profile.name"}。
+// 与To相比不要求json的结构与target一致，json路径不存在的字段保持零值
+func (j *GoJson) Extract(mapping map[string]string, target interface{}) error {
+	_, err := j.ExtractWithMissing(mapping, target)
+	return err
+}
+
+// ExtractWithMissing 与Extract相同，同时按字典序返回json路径不存在的字段路径
+func (j *GoJson) ExtractWithMissing(mapping map[string]string, target interface{}) ([]string, error) {
+	fields := make([]string, 0, len(mapping))
+	for field := range mapping {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var missing []string
+	var assembled interface{} = map[string]interface{}{}
+	for _, field := range fields {
+		val, err := j.valueAt(mapping[field])
+		if err != nil {
+			missing = append(missing, field)
+			continue
+		}
+		if assembled, err = setPathValue(assembled, splitPath(field), deepCopy(val)); err != nil {
+			return missing, fmt.Errorf("field %s: %v", field, err)
+		}
+	}
+
+	bytesArr, err := json.Marshal(assembled)
+	if err != nil {
+		return missing, err
+	}
+	return missing, json.Unmarshal(bytesArr, target)
+}
+
+// ToLenient 与To类似，但逐个字段绑定：json的值无法转换为字段类型时跳过该字段(保持原值)，而不是整体失败。
+// 嵌套的结构体同样逐个字段绑定，json中不存在的字段保持原值。返回被跳过的字段的json路径(GetPath格式，根节点为$)，
+// 只有target不是非nil的指针或数据为nil时返回error
+func (j *GoJson) ToLenient(target interface{}) ([]string, error) {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	if j.data == nil {
+		return nil, errors.New("json data is nil, cannot bind to target")
+	}
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil, fmt.Errorf("%T is not a non-nil pointer", target)
+	}
+	var skipped []string
+	bindLenient("", j.data, v.Elem(), &skipped)
+	return skipped, nil
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*sysjson.Unmarshaler)(nil)).Elem()
+
+// bindLenient 将val绑定到v，结构体逐个字段绑定，无法转换的值的路径追加到skipped
+func bindLenient(path string, val interface{}, v reflect.Value, skipped *[]string) {
+	m, isMap := toMap(val)
+	t := v.Type()
+	if isMap && t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct && !t.Implements(jsonUnmarshalerType) {
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		v, t = v.Elem(), t.Elem()
+	}
+	if !isMap || t.Kind() != reflect.Struct || reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		bytesArr, err := json.Marshal(val)
+		if err != nil {
+			*skipped = append(*skipped, schemaPath(path))
+			return
+		}
+		result := reflect.New(t)
+		if err := json.Unmarshal(bytesArr, result.Interface()); err != nil {
+			*skipped = append(*skipped, schemaPath(path))
+			return
+		}
+		v.Set(result.Elem())
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Name
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		if tagName := strings.Split(tag, ",")[0]; tagName != "" {
+			name = tagName
+		} else if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			bindLenient(path, val, v.Field(i), skipped)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+
+		key, ok := name, false
+		if _, ok = m[key]; !ok {
+			for k := range m {
+				if strings.EqualFold(k, name) {
+					key, ok = k, true
+					break
+				}
+			}
+		}
+		if ok {
+			bindLenient(joinPath(path, escapeKey(key, ".")), m[key], v.Field(i), skipped)
+		}
+	}
+}
+
+// EnablePool 为true时，Get、Index得到的节点从sync.Pool中分配，使用完后可调用Release回收，用于降低高并发下的GC压力。
+// 需要在使用前设置，运行中不要修改
+var EnablePool = false
+
+var nodePool = sync.Pool{
+	New: func() interface{} {
+		return new(GoJson)
+	},
+}
+
+// newNode 分配一个空节点，开启EnablePool时从pool中获取
+func newNode() *GoJson {
+	if EnablePool {
+		return nodePool.Get().(*GoJson)
+	}
+	return &GoJson{}
+}
+
+// Release 回收通过Get、Index得到的节点，未开启EnablePool或根节点时什么都不会发生。
+// 子节点持有父节点的引用，因此只有在该节点以及由它Get、Index得到的所有子节点都不再使用后才能调用，
+// 调用后不能再使用该节点。节点中的数据不受影响，仍然保存在树上
+func (j *GoJson) Release() {
+	if !EnablePool || j.prev == nil {
+		return
+	}
+	j.prev = nil
+	j.prevKey = ""
+	j.prevIndex = 0
+	j.data = nil
+	j.exists = false
+	j.failed = false
+	j.navErr = navError{}
+	nodePool.Put(j)
+}
+
 // NewJsonFromData 从interface{}创建一个json。并不会做什么处理，只是用来包装原始数据。
 func NewJsonFromData(d interface{}) *GoJson {
 	return &GoJson{data: d}
 }
 
+// NewObject 创建一个空的k-v结构，可以链式调用Set，如 NewObject().Set("a", 1).Set("b", 2)
+func NewObject() *GoJson {
+	return &GoJson{data: map[string]interface{}{}}
+}
+
+// NewArray 创建一个空数组，可以链式调用Append，如 NewArray().Append(1).Append(2)
+func NewArray() *GoJson {
+	return &GoJson{data: []interface{}{}}
+}
+
 func getMap(key string, mapBody interface{}) (interface{}, bool) {
 	switch v := mapBody.(type) {
 	case map[string]interface{}:
 		return v[key], true
 	case Dict:
 		return v[key], true
+	case *OrderedDict:
+		return v.values[key], true
+	default:
+		return nil, false
+	}
+}
+
+// lookupMap 与getMap类似，但bool表示key是否存在
+func lookupMap(key string, mapBody interface{}) (interface{}, bool) {
+	m, ok := toMap(mapBody)
+	if !ok {
+		return nil, false
+	}
+	val, ok := m[key]
+	return val, ok
+}
+
+// toMap 将map[string]interface{}、Dict或OrderedDict统一转换为map[string]interface{}，底层数据共享
+func toMap(data interface{}) (map[string]interface{}, bool) {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		return v, true
+	case Dict:
+		return v, true
+	case *OrderedDict:
+		return v.values, true
+	default:
+		return nil, false
+	}
+}
+
+// toSlice 将[]interface{}或List统一转换为[]interface{}，底层数据共享
+func toSlice(data interface{}) ([]interface{}, bool) {
+	switch v := data.(type) {
+	case []interface{}:
+		return v, true
+	case List:
+		return v, true
 	default:
 		return nil, false
 	}
 }
 
-// 取出json object中的所有key
+// Keys 取出json object中的所有key，顺序不固定(OrderedDict按插入顺序)。源数据不是k-v结构时返回空
 func (j *GoJson) Keys() []string {
-	var result []string
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
 
-	if j.data == nil {
-		return result
+	return j.keys()
+}
+
+// SortedKeys 取出json object中的所有key，按字典序排列。源数据不是k-v结构时返回空
+func (j *GoJson) SortedKeys() []string {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	result := j.keys()
+	sort.Strings(result)
+	return result
+}
+
+// Values 取出json object中的所有value，顺序不固定。源数据不是k-v结构时返回nil
+func (j *GoJson) Values() []interface{} {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	jsonMap, ok := toMap(j.data)
+	if !ok {
+		return nil
+	}
+	result := make([]interface{}, 0, len(jsonMap))
+	for _, val := range jsonMap {
+		result = append(result, val)
+	}
+	return result
+}
+
+// Item json object中的一个key-value对
+type Item struct {
+	Key   string
+	Value interface{}
+}
+
+// Items 取出json object中的所有key-value对，按key的字典序排列。源数据不是k-v结构时返回nil
+func (j *GoJson) Items() []Item {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	jsonMap, ok := toMap(j.data)
+	if !ok {
+		return nil
+	}
+	keys := j.keys()
+	sort.Strings(keys)
+	result := make([]Item, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, Item{Key: key, Value: jsonMap[key]})
+	}
+	return result
+}
+
+// Pick 返回一个新的k-v结构，只包含keys中存在的key，值为深复制，与原对象互不影响。
+// 源数据不是k-v结构时返回的GoJson对象 IsNil将为true
+func (j *GoJson) Pick(keys ...string) *GoJson {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	jsonMap, ok := toMap(j.data)
+	if !ok {
+		return &GoJson{}
+	}
+	result := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		if val, ok := jsonMap[key]; ok {
+			result[key] = deepCopy(val)
+		}
+	}
+	return NewJsonFromData(result)
+}
+
+// Omit 返回一个新的k-v结构，包含除keys以外的所有key，值为深复制，与原对象互不影响。
+// 源数据不是k-v结构时返回的GoJson对象 IsNil将为true
+func (j *GoJson) Omit(keys ...string) *GoJson {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	jsonMap, ok := toMap(j.data)
+	if !ok {
+		return &GoJson{}
+	}
+	omitted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		omitted[key] = true
+	}
+	result := make(map[string]interface{}, len(jsonMap))
+	for key, val := range jsonMap {
+		if !omitted[key] {
+			result[key] = deepCopy(val)
+		}
 	}
+	return NewJsonFromData(result)
+}
+
+func (j *GoJson) keys() []string {
+	var result []string
 
-	jsonMap, ok := j.data.(map[string]interface{})
+	if d, ok := j.data.(*OrderedDict); ok {
+		return d.Keys()
+	}
+	jsonMap, ok := toMap(j.data)
 	if !ok {
-		panic(fmt.Sprintf("Input invalid error, your input json is: %s", ToString(j)))
+		return result
 	}
-	for key, _ := range jsonMap {
+	for key := range jsonMap {
 		result = append(result, key)
 	}
 	return result
 }
 
+// deleteMap 删除k-v结构中的key，*OrderedDict经过Delete以同时维护key的顺序。mapBody不是k-v结构时返回false
+func deleteMap(key string, mapBody interface{}) bool {
+	switch v := mapBody.(type) {
+	case map[string]interface{}:
+		delete(v, key)
+	case Dict:
+		delete(v, key)
+	case *OrderedDict:
+		v.Delete(key)
+	default:
+		return false
+	}
+	return true
+}
+
 func setMap(key string, mapBody, data interface{}) bool {
 	var val interface{}
 	if value, ok := data.(*GoJson); ok {
-		val = value.Value()
+		val = value.data
 	} else {
 		val = data
 	}
@@ -168,6 +761,9 @@ func setMap(key string, mapBody, data interface{}) bool {
 	case Dict:
 		v[key] = val
 		return true
+	case *OrderedDict:
+		v.Set(key, val)
+		return true
 	default:
 		return false
 	}
@@ -176,8 +772,14 @@ func setMap(key string, mapBody, data interface{}) bool {
 func getSlice(key int, sliceBody interface{}) (interface{}, bool) {
 	switch v := sliceBody.(type) {
 	case []interface{}:
+		if key < 0 || key >= len(v) {
+			return nil, true
+		}
 		return v[key], true
 	case List:
+		if key < 0 || key >= len(v) {
+			return nil, true
+		}
 		return v[key], true
 	default:
 		return nil, false
@@ -187,7 +789,7 @@ func getSlice(key int, sliceBody interface{}) (interface{}, bool) {
 func appendSlice(sliceBody, data interface{}) (interface{}, bool) {
 	var val interface{}
 	if value, ok := data.(*GoJson); ok {
-		val = value.Value()
+		val = value.data
 	} else {
 		val = data
 	}
@@ -204,40 +806,92 @@ func appendSlice(sliceBody, data interface{}) (interface{}, bool) {
 	}
 }
 
-func setSlice(index int, sliceBody, data interface{}) bool {
+// MaxSliceGrow 按下标写入超出数组长度时最多补齐的null个数，超出时不写入，
+// 避免 Set(100000000, x) 这样的下标分配巨大的数组
+var MaxSliceGrow = 1024
+
+// growSlice 用nil将v补齐到包含index，需要补齐的个数超过MaxSliceGrow时返回false
+func growSlice(v []interface{}, index int) ([]interface{}, bool) {
+	if index-len(v) > MaxSliceGrow {
+		return nil, false
+	}
+	for len(v) <= index {
+		v = append(v, nil)
+	}
+	return v, true
+}
+
+// setSlice 设置数组index位置的值，index超出长度时先用nil补齐(最多MaxSliceGrow个)，负数从末尾开始计算。返回设置后的数组
+func setSlice(index int, sliceBody, data interface{}) (interface{}, bool) {
 	var val interface{}
 	if value, ok := data.(*GoJson); ok {
-		val = value.Value()
+		val = value.data
 	} else {
 		val = data
 	}
 
 	switch v := sliceBody.(type) {
 	case []interface{}:
+		if index < 0 {
+			index += len(v)
+		}
+		if index < 0 {
+			return nil, false
+		}
+		v, ok := growSlice(v, index)
+		if !ok {
+			return nil, false
+		}
 		v[index] = val
-		return true
+		return v, true
 	case List:
-		v[index] = val
-		return true
+		if index < 0 {
+			index += len(v)
+		}
+		if index < 0 {
+			return nil, false
+		}
+		l, ok := growSlice(v, index)
+		if !ok {
+			return nil, false
+		}
+		l[index] = val
+		return List(l), true
 	default:
-		return false
+		return nil, false
+	}
+}
+
+// clampIndex 将python风格的下标(负数从末尾计算)限制在[0, length]之间
+func clampIndex(index, length int) int {
+	if index < 0 {
+		index += length
 	}
+	if index < 0 {
+		return 0
+	}
+	if index > length {
+		return length
+	}
+	return index
 }
 
 func insertSlice(index int, sliceBody, data interface{}) (interface{}, bool) {
 	var val interface{}
 	if value, ok := data.(*GoJson); ok {
-		val = value.Value()
+		val = value.data
 	} else {
 		val = data
 	}
 
 	switch v := sliceBody.(type) {
 	case []interface{}:
+		index = clampIndex(index, len(v))
 		rear := append([]interface{}{}, v[index:]...)
 		v = append(v[0:index], val)
 		return append(v, rear...), true
 	case List:
+		index = clampIndex(index, len(v))
 		rear := append([]interface{}{}, v[index:]...)
 		v = append(v[0:index], val)
 		return append(v, rear...), true
@@ -246,26 +900,66 @@ func insertSlice(index int, sliceBody, data interface{}) (interface{}, bool) {
 	}
 }
 
-// Get 获取一个key值。返回GoJson对象
-func (j *GoJson) Get(key string) *GoJson {
-	m, ok := getMap(key, j.data)
-	if !ok {
-		return &GoJson{
-			prev:    j,
-			prevKey: key,
-			data:    nil,
+func removeSlice(index int, sliceBody interface{}) (interface{}, bool) {
+	switch v := sliceBody.(type) {
+	case []interface{}:
+		if index < 0 {
+			index += len(v)
+		}
+		if index < 0 || index >= len(v) {
+			return v, true
+		}
+		return append(v[:index], v[index+1:]...), true
+	case List:
+		if index < 0 {
+			index += len(v)
+		}
+		if index < 0 || index >= len(v) {
+			return v, true
 		}
+		return append(v[:index], v[index+1:]...), true
+	default:
+		return nil, false
 	}
+}
 
-	return &GoJson{
-		prev:    j,
-		prevKey: key,
-		data:    m,
+// Get 获取一个key值。返回GoJson对象，当前对象不是k-v结构时返回的对象不与当前对象关联
+func (j *GoJson) Get(key string) *GoJson {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	return j.get(key)
+}
+
+// get 与Get相同，但不加锁，调用方已持有读锁
+func (j *GoJson) get(key string) *GoJson {
+	node := newNode()
+
+	// 只做一次类型判断和一次map查找，Get是最常用的方法，避免getMap加lookupMap的重复开销
+	jsonMap, ok := toMap(j.data)
+	if !ok {
+		// 当前对象不是map时不与其关联，否则对返回对象的修改会按数组下标写回
+		node.prevKey = key
+		j.navigateFailed(node, navError{key: key, reason: "is not a map"})
+		return node
 	}
+	node.prev = j
+	node.prevKey = key
+
+	node.data, node.exists = jsonMap[key]
+	if !node.exists {
+		j.navigateFailed(node, navError{key: key})
+	}
+	return node
 }
 
 // 获得key对应的string，若key不存在，则返回空字符串
 func (j *GoJson) GetString(key string) string {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
 	m, ok := getMap(key, j.data)
 	if !ok {
 		return ""
@@ -276,370 +970,3197 @@ func (j *GoJson) GetString(key string) string {
 		prevKey: key,
 		data:    m,
 	}
-	return ToString(g)
+	return g.string()
 }
 
-// maintainParent 维护这个节点与父节点的关系
-func maintainParent(child *GoJson) {
-	if child.prev == nil {
-		return
+// GetInt 获得key对应的int，若key不存在或无法转换，则返回0和error
+func (j *GoJson) GetInt(key string) (int, error) {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	m, ok := getMap(key, j.data)
+	if !ok || m == nil {
+		return 0, fmt.Errorf("key %s not found", key)
 	}
+	return ToInt(m)
+}
 
-	switch child.prev.Value().(type) {
-	case map[string]interface{}:
-		child.prev.Set(child.prevKey, child)
-	case []interface{}:
-		child.prev.Set(child.prevIndex, child)
+// GetUint64 获得key对应的uint64，若key不存在、为负数或无法转换，则返回0和error
+func (j *GoJson) GetUint64(key string) (uint64, error) {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	m, ok := getMap(key, j.data)
+	if !ok || m == nil {
+		return 0, fmt.Errorf("key %s not found", key)
 	}
+	return ToUint64(m)
 }
 
-// Append 往数组中添加值并返回自身，当json不为slice，将直接返回自身
-func (j *GoJson) Append(val interface{}) *GoJson {
-	var v interface{}
-	if value, ok := val.(*GoJson); ok {
-		v = value.Value()
-	} else {
-		v = val
+// GetFloat64 获得key对应的float64，若key不存在或无法转换，则返回0和error
+func (j *GoJson) GetFloat64(key string) (float64, error) {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	m, ok := getMap(key, j.data)
+	if !ok || m == nil {
+		return 0, fmt.Errorf("key %s not found", key)
 	}
+	return ToFloat64(m)
+}
 
-	data, ok := appendSlice(j.data, v)
-	if !ok {
-		log.Println(fmt.Sprintf("%v is not slice cannot append", j.data))
-		return j
+// GetBool 获得key对应的bool，若key不存在或无法转换，则返回false和error
+func (j *GoJson) GetBool(key string) (bool, error) {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	m, ok := getMap(key, j.data)
+	if !ok || m == nil {
+		return false, fmt.Errorf("key %s not found", key)
 	}
-	j.data = data
+	return ToBool(m)
+}
 
-	maintainParent(j)
-	return j
+// GetArray 获得key对应的数组，若key不存在或不是数组，则返回error
+func (j *GoJson) GetArray(key string) ([]interface{}, error) {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	m, ok := lookupMap(key, j.data)
+	if !ok {
+		return nil, fmt.Errorf("key %s not found", key)
+	}
+	l, ok := toSlice(m)
+	if !ok {
+		return nil, fmt.Errorf("key %s: %v is not array", key, m)
+	}
+	return l, nil
 }
 
-// Insert 往数组中添加值，当json不为slice，返回自身，什么都不会发生
-func (j *GoJson) Insert(index int, val interface{}) *GoJson {
-	v, ok := insertSlice(index, j.data, val)
+// GetMap 获得key对应的k-v结构，若key不存在或不是k-v结构，则返回error
+func (j *GoJson) GetMap(key string) (map[string]interface{}, error) {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	m, ok := lookupMap(key, j.data)
 	if !ok {
-		log.Println(fmt.Sprintf("%v is not slice cannot insert", j.data))
-		return j
+		return nil, fmt.Errorf("key %s not found", key)
 	}
-	j.data = v
-	maintainParent(j)
-	return j
+	result, ok := toMap(m)
+	if !ok {
+		return nil, fmt.Errorf("key %s: %v is not map", key, m)
+	}
+	return result, nil
 }
 
-// IsNil 判定data是不是空，常用来检测NewJson, Get, Index的结果是否为空
-func (j *GoJson) IsNil() bool {
-	if j.data == nil {
-		return true
+// GetTime 获得key对应的时间，解析规则见ToTime，若key不存在或无法解析，则返回零值和error
+func (j *GoJson) GetTime(key string, layouts ...string) (time.Time, error) {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	m, ok := getMap(key, j.data)
+	if !ok || m == nil {
+		return time.Time{}, fmt.Errorf("key %s not found", key)
 	}
-	return false
+	return ToTime(m, layouts...)
 }
 
-// IsSlice 判定GoJson对象源数据是不是数组结构
-func (j *GoJson) IsSlice() bool {
-	switch j.data.(type) {
-	case List:
-		return true
-	case []interface{}:
-		return true
-	default:
-		return false
+// GetBytesDecoded 获得key对应的字符串，并按标准base64解码，若key不存在或解码失败，则返回error
+func (j *GoJson) GetBytesDecoded(key string) ([]byte, error) {
+	return j.getBytesDecoded(key, base64.StdEncoding, base64.RawStdEncoding)
+}
+
+// GetBytesDecodedURL 与GetBytesDecoded相同，但使用URL安全的base64编码(以-和_代替+和/)
+func (j *GoJson) GetBytesDecodedURL(key string) ([]byte, error) {
+	return j.getBytesDecoded(key, base64.URLEncoding, base64.RawURLEncoding)
+}
+
+func (j *GoJson) getBytesDecoded(key string, encodings ...*base64.Encoding) ([]byte, error) {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	m, ok := getMap(key, j.data)
+	if !ok || m == nil {
+		return nil, fmt.Errorf("key %s not found", key)
 	}
+	return toBytes(m, encodings...)
 }
 
-// IsMap 判定GoJson对象源数据是不是k-v结构
-func (j *GoJson) IsMap() bool {
-	switch j.data.(type) {
-	case Dict, map[string]interface{}:
-		return true
-	default:
-		return false
+// GetStringOr 获得key对应的string，若key不存在或为null，则返回def
+func (j *GoJson) GetStringOr(key, def string) string {
+	v := j.Get(key)
+	if v.IsNil() {
+		return def
 	}
+	return v.String()
 }
 
-// Index 传入位置，获取slice对应位置的GoJson对象。如果这个对象不存在，返回的GoJson对象 IsNil将为true
-func (j *GoJson) Index(key int) *GoJson {
-	v, ok := getSlice(key, j.data)
-	if !ok {
-		return &GoJson{
-			prev:      j,
-			prevIndex: key,
-			data:      nil,
+// GetIntOr 获得key对应的int，若key不存在或无法转换，则返回def
+func (j *GoJson) GetIntOr(key string, def int) int {
+	v, err := j.GetInt(key)
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// GetFloat64Or 获得key对应的float64，若key不存在或无法转换，则返回def
+func (j *GoJson) GetFloat64Or(key string, def float64) float64 {
+	v, err := j.GetFloat64(key)
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// GetBoolOr 获得key对应的bool，若key不存在或无法转换，则返回def
+func (j *GoJson) GetBoolOr(key string, def bool) bool {
+	v, err := j.GetBool(key)
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// splitPath 按"."切分路径，"\"用于转义下一个字符，如 "a\.b" 表示key为"a.b"
+func splitPath(path string) []string {
+	return splitKey(path, ".")
+}
+
+// splitKey 按sep切分路径，"\"用于转义下一个字符
+func splitKey(path, sep string) []string {
+	var segments []string
+	var current strings.Builder
+	for i := 0; i < len(path); {
+		switch {
+		case path[i] == '\\' && i+1 < len(path):
+			_, size := utf8.DecodeRuneInString(path[i+1:])
+			current.WriteString(path[i+1 : i+1+size])
+			i += 1 + size
+		case sep != "" && strings.HasPrefix(path[i:], sep):
+			segments = append(segments, current.String())
+			current.Reset()
+			i += len(sep)
+		default:
+			current.WriteByte(path[i])
+			i++
 		}
 	}
+	return append(segments, current.String())
+}
 
-	return &GoJson{
-		prev:      j,
-		prevIndex: key,
-		data:      v,
+// escapeKey 对key中的"\"和sep进行转义，与splitKey对应
+func escapeKey(key, sep string) string {
+	key = strings.Replace(key, "\\", "\\\\", -1)
+	if sep == "" {
+		return key
 	}
+	return strings.Replace(key, sep, "\\"+sep, -1)
 }
 
-// Set 对当前的GoJson对象对应key设置值
-func (j *GoJson) Set(key interface{}, val interface{}) *GoJson {
-	switch v := key.(type) {
-	case string:
-		ok := setMap(v, j.data, val)
-		if !ok {
-			log.Println(fmt.Sprintf("%v is not map cannot set", j.data))
-			return j
+// GetPath 按"."分隔的路径获取值，如 "data.items.0.name"。数组上的数字段作为下标，其余作为key。
+// 任意一段不存在时，返回的GoJson对象 IsNil将为true。数组下标越界或不是数字时，返回的对象不与原对象关联，对其修改不会写回
+func (j *GoJson) GetPath(path string) *GoJson {
+	if path == "" {
+		return j
+	}
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	node := j
+	for _, seg := range splitPath(path) {
+		if _, ok := toSlice(node.data); ok {
+			index, err := strconv.Atoi(seg)
+			if err != nil || index < 0 || index >= node.len() {
+				return &GoJson{}
+			}
+			node = node.index(index)
+			continue
 		}
-	case int:
-		ok := setSlice(v, j.data, val)
+		node = node.get(seg)
+	}
+	return node
+}
+
+// valueAt 按GetPath格式的路径直接取值，不创建中间节点，失败时返回的error包含路径
+func (j *GoJson) valueAt(path string) (interface{}, error) {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	node := j.data
+	if path == "" {
+		return node, nil
+	}
+	for _, seg := range splitPath(path) {
+		if l, ok := toSlice(node); ok {
+			index, err := strconv.Atoi(seg)
+			if err != nil || index < 0 || index >= len(l) {
+				return nil, fmt.Errorf("path %s: index %s out of range", path, seg)
+			}
+			node = l[index]
+			continue
+		}
+		val, ok := lookupMap(seg, node)
 		if !ok {
-			log.Println(fmt.Sprintf("%v is not map cannot set", j.data))
-			return j
+			return nil, fmt.Errorf("path %s: key %s not found", path, seg)
 		}
+		node = val
 	}
-	return j
+	return node, nil
 }
 
-// Remove 删除GoJson的一个key。目前不能操作slice，只能操作k-v结构
-func (j *GoJson) Remove(key interface{}) *GoJson {
-	switch keyVal := key.(type) {
-	case string:
-		if j.IsMap() {
-			switch v := j.data.(type) {
-			case map[string]interface{}:
-				delete(v, keyVal)
-			case Dict:
-				delete(v, keyVal)
-			}
+// GetPathOr 按GetPath格式的路径获取值，路径不存在或值为null时返回包装了def的GoJson对象，如 cfg.GetPathOr("server.timeout", 30)。
+// def为*GoJson时使用其数据。返回的默认值是独立的对象，不会写入当前对象
+func (j *GoJson) GetPathOr(path string, def interface{}) *GoJson {
+	if node := j.GetPath(path); !node.IsNil() {
+		return node
+	}
+	if value, ok := def.(*GoJson); ok {
+		if value == nil {
+			return NewJsonFromData(nil)
 		}
-	case int:
-		// 暂时未实现rm slice功能
+		def = value.Value()
 	}
-	return j
+	return NewJsonFromData(def)
 }
 
-// Value 返回GoJson对象的真实数据
-func (j *GoJson) Value() interface{} {
-	v := j.data
-	return v
+// IntAt 按GetPath格式的路径获取值并转换为int，路径不存在或无法转换时返回error
+func (j *GoJson) IntAt(path string) (int, error) {
+	val, err := j.valueAt(path)
+	if err != nil {
+		return 0, err
+	}
+	v, err := ToInt(val)
+	if err != nil {
+		return 0, fmt.Errorf("path %s: %v", path, err)
+	}
+	return v, nil
 }
 
-// Len 返回数组对象的长度，如果源数据不是数组，则返回0
-func (j *GoJson) Len() int {
-	switch v := j.data.(type) {
+// StringAt 按GetPath格式的路径获取值并转换为string，路径不存在或值为null时返回error
+func (j *GoJson) StringAt(path string) (string, error) {
+	val, err := j.valueAt(path)
+	if err != nil {
+		return "", err
+	}
+	if val == nil {
+		return "", fmt.Errorf("path %s: value is null", path)
+	}
+	return (&GoJson{data: val}).string(), nil
+}
+
+// Float64At 按GetPath格式的路径获取值并转换为float64，路径不存在或无法转换时返回error
+func (j *GoJson) Float64At(path string) (float64, error) {
+	val, err := j.valueAt(path)
+	if err != nil {
+		return 0, err
+	}
+	v, err := ToFloat64(val)
+	if err != nil {
+		return 0, fmt.Errorf("path %s: %v", path, err)
+	}
+	return v, nil
+}
+
+// BoolAt 按GetPath格式的路径获取值并转换为bool，转换规则见ToBool，路径不存在或无法转换时返回error
+func (j *GoJson) BoolAt(path string) (bool, error) {
+	val, err := j.valueAt(path)
+	if err != nil {
+		return false, err
+	}
+	v, err := ToBool(val)
+	if err != nil {
+		return false, fmt.Errorf("path %s: %v", path, err)
+	}
+	return v, nil
+}
+
+// setPathValue 沿路径写入val，缺失的中间节点按下一段的类型创建map或slice，返回写入后的节点
+func setPathValue(node interface{}, segments []string, val interface{}) (interface{}, error) {
+	if len(segments) == 0 {
+		return val, nil
+	}
+
+	seg := segments[0]
+	index, err := strconv.Atoi(seg)
+	isIndex := err == nil && index >= 0
+	if node == nil {
+		if isIndex {
+			node = []interface{}{}
+		} else {
+			node = map[string]interface{}{}
+		}
+	}
+
+	switch v := node.(type) {
+	case map[string]interface{}:
+		child, err := setPathValue(v[seg], segments[1:], val)
+		if err != nil {
+			return nil, err
+		}
+		v[seg] = child
+		return v, nil
+	case Dict:
+		child, err := setPathValue(v[seg], segments[1:], val)
+		if err != nil {
+			return nil, err
+		}
+		v[seg] = child
+		return v, nil
+	case *OrderedDict:
+		child, err := setPathValue(v.values[seg], segments[1:], val)
+		if err != nil {
+			return nil, err
+		}
+		v.Set(seg, child)
+		return v, nil
 	case []interface{}:
-		return len(v)
+		if !isIndex {
+			return nil, fmt.Errorf("%s is not a valid index of %v", seg, v)
+		}
+		v, ok := growSlice(v, index)
+		if !ok {
+			return nil, fmt.Errorf("index %d is more than %d past the end of array", index, MaxSliceGrow)
+		}
+		child, err := setPathValue(v[index], segments[1:], val)
+		if err != nil {
+			return nil, err
+		}
+		v[index] = child
+		return v, nil
 	case List:
-		return len(v)
+		if !isIndex {
+			return nil, fmt.Errorf("%s is not a valid index of %v", seg, v)
+		}
+		v, ok := growSlice(v, index)
+		if !ok {
+			return nil, fmt.Errorf("index %d is more than %d past the end of array", index, MaxSliceGrow)
+		}
+		child, err := setPathValue(v[index], segments[1:], val)
+		if err != nil {
+			return nil, err
+		}
+		v[index] = child
+		return v, nil
 	default:
-		return 0
+		return nil, fmt.Errorf("%v is not map or slice, cannot set %s", v, seg)
 	}
 }
 
-// String方法返回GoJson对象的字符串值
-func (j *GoJson) String() string {
-	if j.data == nil {
-		return ""
+// SetPath 按"."分隔的路径设置值，缺失的中间节点会自动创建：数字段创建数组，其余创建map。
+// 数字段超出数组长度时用null补齐，需要补齐的个数超过MaxSliceGrow时返回error。
+// path为""时与GetPath一致表示当前节点，替换当前节点的值。中间节点已存在且不是map或数组时返回error
+func (j *GoJson) SetPath(path string, val interface{}) error {
+	r := j.root()
+	r.Lock()
+	defer r.Unlock()
+	if err := r.checkFrozen("set"); err != nil {
+		return err
 	}
-	switch j.data.(type) {
-	case map[string]interface{}, []interface{}, Dict, List:
-		buffer := &bytes.Buffer{}
-		encoder := json.NewEncoder(buffer)
-		//encoder.SetEscapeHTML(false)
-		err := encoder.Encode(j.data)
+
+	var v interface{}
+	if value, ok := val.(*GoJson); ok {
+		v = value.data
+	} else {
+		v = val
+	}
+
+	if path == "" {
+		j.data = v
+		maintainParent(j)
+		return nil
+	}
+	data, err := setPathValue(j.data, splitPath(path), v)
+	if err != nil {
+		return err
+	}
+	j.data = data
+
+	maintainParent(j)
+	return nil
+}
+
+// detachPathValue 删除segments对应的值并返回删除后的node和被删除的值。
+// 路径上的map和数组都会复制一份再修改(保留Dict、List类型)，原有数据保持不变
+func detachPathValue(node interface{}, segments []string) (interface{}, interface{}, error) {
+	seg := segments[0]
+	if m, ok := toMap(node); ok {
+		child, ok := m[seg]
+		if !ok {
+			return nil, nil, fmt.Errorf("key %s not found", seg)
+		}
+		result := make(map[string]interface{}, len(m))
+		for key, val := range m {
+			result[key] = val
+		}
+		var container interface{} = result
+		switch v := node.(type) {
+		case Dict:
+			container = Dict(result)
+		case *OrderedDict:
+			container = &OrderedDict{keys: v.Keys(), values: result}
+		}
+		if len(segments) == 1 {
+			deleteMap(seg, container)
+			return container, child, nil
+		}
+		newChild, val, err := detachPathValue(child, segments[1:])
 		if err != nil {
-			log.Println("convert to String is error", err)
-			return ""
+			return nil, nil, err
 		}
-		return buffer.String()
-	default:
-		return ToString(j.data)
+		result[seg] = newChild
+		return container, val, nil
+	}
+	if l, ok := toSlice(node); ok {
+		index, err := strconv.Atoi(seg)
+		if err != nil || index < 0 || index >= len(l) {
+			return nil, nil, fmt.Errorf("index %s out of range", seg)
+		}
+		result := make([]interface{}, len(l))
+		copy(result, l)
+		val := l[index]
+		if len(segments) == 1 {
+			result = append(result[:index], result[index+1:]...)
+		} else {
+			var newChild interface{}
+			if newChild, val, err = detachPathValue(l[index], segments[1:]); err != nil {
+				return nil, nil, err
+			}
+			result[index] = newChild
+		}
+		if _, ok := node.(List); ok {
+			return List(result), val, nil
+		}
+		return result, val, nil
+	}
+	return nil, nil, fmt.Errorf("%v is not map or slice", node)
+}
+
+// MoveSubtree 将fromPath的值移动到toPath，路径为GetPath格式，toPath缺失的中间节点会自动创建。
+// fromPath不存在、toPath在fromPath之下或无法设置toPath时返回error，此时当前对象保持不变。
+// fromPath路径上的map和数组会被复制，之前通过Get、Index得到的这些节点不会再与之关联
+func (j *GoJson) MoveSubtree(fromPath, toPath string) error {
+	fromSegments := splitPath(fromPath)
+	toSegments := splitPath(toPath)
+	if fromPath == "" || toPath == "" {
+		return errors.New("cannot move from or to the whole document")
+	}
+	if len(toSegments) > len(fromSegments) {
+		prefix := true
+		for i, seg := range fromSegments {
+			if toSegments[i] != seg {
+				prefix = false
+				break
+			}
+		}
+		if prefix {
+			return fmt.Errorf("cannot move %s into its own child %s", fromPath, toPath)
+		}
+	}
+
+	r := j.root()
+	r.Lock()
+	defer r.Unlock()
+	if err := r.checkFrozen("move"); err != nil {
+		return err
+	}
+
+	data, val, err := detachPathValue(j.data, fromSegments)
+	if err != nil {
+		return fmt.Errorf("path %s: %v", fromPath, err)
 	}
+	if data, err = setPathValue(data, toSegments, val); err != nil {
+		return fmt.Errorf("path %s: %v", toPath, err)
+	}
+	j.data = data
+
+	maintainParent(j)
+	return nil
+}
+
+func flatten(prefix, sep string, val interface{}, result map[string]interface{}) {
+	join := func(seg string) string {
+		if prefix == "" {
+			return seg
+		}
+		return prefix + sep + seg
+	}
+
+	if m, ok := toMap(val); ok && len(m) > 0 {
+		for key, child := range m {
+			flatten(join(escapeKey(key, sep)), sep, child, result)
+		}
+		return
+	}
+	if l, ok := toSlice(val); ok && len(l) > 0 {
+		for i, child := range l {
+			flatten(join(strconv.Itoa(i)), sep, child, result)
+		}
+		return
+	}
+	result[prefix] = val
+}
+
+// Flatten 将嵌套结构展开为一层，key为"."连接的路径，如 {"a":{"b":[1,2]}} 展开为 {"a.b.0":1,"a.b.1":2}。
+// key中的"."会被转义为"\."，空的map和数组保留为叶子节点，源数据不是map或数组时返回空map
+func (j *GoJson) Flatten() map[string]interface{} {
+	return j.FlattenWith(".")
+}
+
+// FlattenWith 与Flatten相同，但使用sep作为路径分隔符
+func (j *GoJson) FlattenWith(sep string) map[string]interface{} {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	result := make(map[string]interface{})
+	_, isMap := toMap(j.data)
+	_, isSlice := toSlice(j.data)
+	if !isMap && !isSlice {
+		return result
+	}
+	flatten("", sep, j.data, result)
+	return result
+}
+
+// Unflatten Flatten的逆操作，按"."分隔的key还原嵌套结构，数字段还原为数组
+func Unflatten(flat map[string]interface{}) *GoJson {
+	return UnflattenWith(flat, ".")
+}
+
+// UnflattenWith 与Unflatten相同，但使用sep作为路径分隔符。路径冲突的key会被忽略并打印日志
+func UnflattenWith(flat map[string]interface{}, sep string) *GoJson {
+	keys := make([]string, 0, len(flat))
+	for key := range flat {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var data interface{} = map[string]interface{}{}
+	for _, key := range keys {
+		result, err := setPathValue(data, splitKey(key, sep), flat[key])
+		if err != nil {
+			log.Println(fmt.Sprintf("unflatten %s is error: %v", key, err))
+			continue
+		}
+		data = result
+	}
+	return &GoJson{data: data}
+}
+
+// ToStringMap 按Flatten展开为一层，并将所有叶子节点转换为字符串，适用于环境变量、标签等只支持字符串的场景。
+// key为"."连接的路径，数组元素以下标作为路径的一段，如 {"a":[1,2]} 转换为 {"a.0":"1","a.1":"2"}；
+// 标量通过ToString转换，null为"null"，空的map和数组编码为"{}"和"[]"。源数据不是k-v结构时返回error
+func (j *GoJson) ToStringMap() (map[string]string, error) {
+	if !j.IsMap() {
+		return nil, fmt.Errorf("%v is not map", j.data)
+	}
+	flat := j.Flatten()
+	result := make(map[string]string, len(flat))
+	for key, val := range flat {
+		switch val.(type) {
+		case nil, map[string]interface{}, Dict, *OrderedDict, []interface{}, List:
+			b, err := marshal(val)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = string(b)
+		default:
+			result[key] = ToString(val)
+		}
+	}
+	return result, nil
+}
+
+// inferValue 推断字符串对应的json值：true、false、null、数字以及json编码的map和数组，其余保持为字符串
+func inferValue(s string) interface{} {
+	switch s {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+	if s == "" {
+		return s
+	}
+	switch s[0] {
+	case '{', '[':
+		if js, err := NewJsonFromStringE(s); err == nil {
+			return js.data
+		}
+	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		if sysjson.Valid([]byte(s)) {
+			return sysjson.Number(s)
+		}
+	}
+	return s
+}
+
+// NewJsonFromStringMap ToStringMap的逆操作，按"."分隔的key还原嵌套结构，数字段还原为数组，
+// 值为true、false、null、数字或json编码的map和数组时还原为对应的类型，其余为字符串
+func NewJsonFromStringMap(m map[string]string) *GoJson {
+	flat := make(map[string]interface{}, len(m))
+	for key, val := range m {
+		flat[key] = inferValue(val)
+	}
+	return Unflatten(flat)
+}
+
+// maintainParent 维护这个节点与父节点的关系
+func maintainParent(child *GoJson) {
+	if child.prev == nil {
+		return
+	}
+
+	switch child.prev.data.(type) {
+	case map[string]interface{}, Dict, *OrderedDict:
+		child.prev.set(child.prevKey, child)
+	case []interface{}, List:
+		child.prev.set(child.prevIndex, child)
+	}
+}
+
+// Append 往数组中添加值并返回自身，当json不为slice，将直接返回自身
+func (j *GoJson) Append(val interface{}) *GoJson {
+	r := j.root()
+	r.Lock()
+	defer r.Unlock()
+	if err := r.checkFrozen("append"); err != nil {
+		log.Println(err)
+		return j
+	}
+
+	var v interface{}
+	if value, ok := val.(*GoJson); ok {
+		v = value.data
+	} else {
+		v = val
+	}
+
+	data, ok := appendSlice(j.data, v)
+	if !ok {
+		log.Println(fmt.Sprintf("%v is not slice cannot append", j.data))
+		return j
+	}
+	j.data = data
+
+	maintainParent(j)
+	return j
+}
+
+// AppendMany 往数组中一次添加多个值并返回自身，当json不为slice，将直接返回自身
+func (j *GoJson) AppendMany(vals ...interface{}) *GoJson {
+	r := j.root()
+	r.Lock()
+	defer r.Unlock()
+	if err := r.checkFrozen("append"); err != nil {
+		log.Println(err)
+		return j
+	}
+
+	unwrapped := make([]interface{}, 0, len(vals))
+	for _, val := range vals {
+		if value, ok := val.(*GoJson); ok {
+			unwrapped = append(unwrapped, value.data)
+		} else {
+			unwrapped = append(unwrapped, val)
+		}
+	}
+
+	switch v := j.data.(type) {
+	case []interface{}:
+		j.data = append(v, unwrapped...)
+	case List:
+		j.data = append(v, unwrapped...)
+	default:
+		log.Println(fmt.Sprintf("%v is not slice cannot append", j.data))
+		return j
+	}
+
+	maintainParent(j)
+	return j
+}
+
+// Prepend 往数组的最前面添加值并返回自身，当json不为slice，将直接返回自身
+func (j *GoJson) Prepend(val interface{}) *GoJson {
+	r := j.root()
+	r.Lock()
+	defer r.Unlock()
+	if err := r.checkFrozen("prepend"); err != nil {
+		log.Println(err)
+		return j
+	}
+
+	var v interface{}
+	if value, ok := val.(*GoJson); ok {
+		v = value.data
+	} else {
+		v = val
+	}
+
+	switch l := j.data.(type) {
+	case []interface{}:
+		j.data = append([]interface{}{v}, l...)
+	case List:
+		j.data = List(append([]interface{}{v}, l...))
+	default:
+		log.Println(fmt.Sprintf("%v is not slice cannot prepend", j.data))
+		return j
+	}
+
+	maintainParent(j)
+	return j
+}
+
+// Insert 往数组的index位置插入值，当json不为slice，返回自身，什么都不会发生。
+// index大于等于长度时追加到末尾，负数从末尾开始计算，超出开头时插入到最前面
+func (j *GoJson) Insert(index int, val interface{}) *GoJson {
+	r := j.root()
+	r.Lock()
+	defer r.Unlock()
+	if err := r.checkFrozen("insert"); err != nil {
+		log.Println(err)
+		return j
+	}
+
+	if err := j.insert(index, val); err != nil {
+		log.Println(err)
+	}
+	return j
+}
+
+// InsertE 与Insert相同，但json不为slice时返回error
+func (j *GoJson) InsertE(index int, val interface{}) error {
+	r := j.root()
+	r.Lock()
+	defer r.Unlock()
+	if err := r.checkFrozen("insert"); err != nil {
+		return err
+	}
+
+	return j.insert(index, val)
+}
+
+func (j *GoJson) insert(index int, val interface{}) error {
+	v, ok := insertSlice(index, j.data, val)
+	if !ok {
+		return fmt.Errorf("%v is not slice cannot insert", j.data)
+	}
+	j.data = v
+	maintainParent(j)
+	return nil
+}
+
+// Exists 判定通过Get、Index得到的节点对应的key或下标是否真实存在，值为null时同样返回true。
+// 根节点在data不为空时返回true
+func (j *GoJson) Exists() bool {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	if j.prev == nil {
+		return j.data != nil
+	}
+	return j.exists
+}
+
+// IsNil 判定data是不是空，常用来检测NewJson, Get, Index的结果是否为空
+func (j *GoJson) IsNil() bool {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	if j.data == nil {
+		return true
+	}
+	return false
+}
+
+// Or 当前对象IsNil为false时返回自身，否则返回fallback，如 cfg.Get("a").Or(defaults.Get("a"))。
+// 返回的就是原对象本身，对其Set仍会写回各自所在的位置
+func (j *GoJson) Or(fallback *GoJson) *GoJson {
+	if !j.IsNil() {
+		return j
+	}
+	return fallback
+}
+
+// AsArray 当前对象为数组时返回自身；为null时返回一个新的空数组；否则返回只包含当前值的新数组。不会修改原对象
+func (j *GoJson) AsArray() *GoJson {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	if _, ok := toSlice(j.data); ok {
+		return j
+	}
+	if j.data == nil {
+		return NewJsonFromData([]interface{}{})
+	}
+	return NewJsonFromData([]interface{}{j.data})
+}
+
+// IsSlice 判定GoJson对象源数据是不是数组结构
+func (j *GoJson) IsSlice() bool {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	switch j.data.(type) {
+	case List:
+		return true
+	case []interface{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsMap 判定GoJson对象源数据是不是k-v结构
+func (j *GoJson) IsMap() bool {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	switch j.data.(type) {
+	case Dict, map[string]interface{}, *OrderedDict:
+		return true
+	default:
+		return false
+	}
+}
+
+// Index 传入位置，获取slice对应位置的GoJson对象。负数表示从末尾开始计算，如 Index(-1) 为最后一个元素。
+// 如果这个对象不存在或越界，返回的GoJson对象 IsNil将为true。当前对象不是数组或负数下标超出开头时，返回的对象不与当前对象关联，对其修改不会写回
+func (j *GoJson) Index(key int) *GoJson {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	return j.index(key)
+}
+
+// index 与Index相同，但不加锁，调用方已持有读锁
+func (j *GoJson) index(key int) *GoJson {
+	index := key
+	if key < 0 {
+		key += j.len()
+	}
+	node := newNode()
+
+	node.prevIndex = key
+	v, ok := getSlice(key, j.data)
+	if !ok {
+		j.navigateFailed(node, navError{index: index, isIndex: true, reason: "is not an array"})
+		return node
+	}
+
+	node.data = v
+	node.exists = key >= 0 && key < j.len()
+	if !node.exists {
+		j.navigateFailed(node, navError{index: index, isIndex: true})
+	}
+	// 负数下标超出开头时不与父节点关联，否则对其修改会再次按负数下标写回，覆盖其他元素
+	if key >= 0 {
+		node.prev = j
+	}
+	return node
+}
+
+// path 返回从根节点到当前节点的路径，如 a[2].b，用于错误信息
+func (j *GoJson) path() string {
+	if j.prev == nil {
+		return ""
+	}
+	if _, ok := toSlice(j.prev.data); ok {
+		return fmt.Sprintf("%s[%d]", j.prev.path(), j.prevIndex)
+	}
+	return joinPath(j.prev.path(), j.prevKey)
+}
+
+// navigateFailed 记录通过当前节点访问子节点node失败：当前节点已失败时沿用第一次失败的信息，
+// 否则在e中补充当前节点的路径。调用方已持有读锁
+func (j *GoJson) navigateFailed(node *GoJson, e navError) {
+	node.failed = true
+	if j.failed {
+		node.navErr = j.navErr
+		return
+	}
+	e.parent = j.path()
+	node.navErr = e
+}
+
+// Err 返回通过Get、Index链式访问时第一次失败的原因，如 path a[2].b: a[2] is not a map，访问成功时返回nil
+func (j *GoJson) Err() error {
+	if !j.failed {
+		return nil
+	}
+	e := j.navErr
+	var path string
+	if e.isIndex {
+		path = fmt.Sprintf("%s[%d]", e.parent, e.index)
+	} else {
+		path = joinPath(e.parent, e.key)
+	}
+	if e.reason == "" {
+		return fmt.Errorf("path %s: not found", path)
+	}
+	parent := e.parent
+	if parent == "" {
+		parent = "root"
+	}
+	return fmt.Errorf("path %s: %s %s", path, parent, e.reason)
+}
+
+// GetCI 与Get相同，但key大小写不敏感，如 userId 可以匹配到 UserId。完全相等的key优先，
+// 有多个匹配时取字典序最小的key。返回的节点对应实际的key，对其Set会写回该key
+func (j *GoJson) GetCI(key string) *GoJson {
+	return j.Get(j.matchKeyCI(key))
+}
+
+// matchKeyCI 返回与key大小写不敏感相等的实际key，没有匹配时返回key本身
+func (j *GoJson) matchKeyCI(key string) string {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	jsonMap, ok := toMap(j.data)
+	if !ok {
+		return key
+	}
+	if _, ok := jsonMap[key]; ok {
+		return key
+	}
+	match, found := key, false
+	for k := range jsonMap {
+		if strings.EqualFold(k, key) && (!found || k < match) {
+			match, found = k, true
+		}
+	}
+	return match
+}
+
+// Lookup 与Get相同，bool表示key是否真实存在，值为null时同样返回true。当前对象不是k-v结构时返回false
+func (j *GoJson) Lookup(key string) (*GoJson, bool) {
+	node := j.Get(key)
+	return node, node.exists
+}
+
+// GetFirst 依次查找keys，返回第一个存在且不为null的key对应的节点，用于兼容字段改名，如 j.GetFirst("userName", "user_name")。
+// 都不满足时返回的GoJson对象 IsNil将为true：其中有值为null的key时返回第一个这样的节点，Exists为true，否则Exists为false
+func (j *GoJson) GetFirst(keys ...string) *GoJson {
+	var nullNode, missingNode *GoJson
+	for _, key := range keys {
+		node, ok := j.Lookup(key)
+		switch {
+		case ok && !node.IsNil():
+			return node
+		case ok && nullNode == nil:
+			nullNode = node
+		case !ok && missingNode == nil:
+			missingNode = node
+		}
+	}
+	if nullNode != nil {
+		return nullNode
+	}
+	if missingNode != nil {
+		return missingNode
+	}
+	return &GoJson{}
+}
+
+// LookupIndex 与Index相同，bool表示下标是否在数组范围内，负数从末尾开始计算。当前对象不是数组时返回false
+func (j *GoJson) LookupIndex(index int) (*GoJson, bool) {
+	node := j.Index(index)
+	return node, node.exists
+}
+
+// Set 对当前的GoJson对象对应key设置值。key为int时设置数组元素，超出长度时先用null补齐，
+// 需要补齐的个数超过MaxSliceGrow时什么都不会发生，负数从末尾开始计算
+func (j *GoJson) Set(key interface{}, val interface{}) *GoJson {
+	r := j.root()
+	r.Lock()
+	defer r.Unlock()
+	if err := r.checkFrozen("set"); err != nil {
+		log.Println(err)
+		return j
+	}
+
+	return j.set(key, val)
+}
+
+// set 不加锁的Set，供已持有锁的方法调用
+func (j *GoJson) set(key interface{}, val interface{}) *GoJson {
+	switch v := key.(type) {
+	case string:
+		ok := setMap(v, j.data, val)
+		if !ok {
+			log.Println(fmt.Sprintf("%v is not map cannot set", j.data))
+			return j
+		}
+	case int:
+		oldLen := j.len()
+		data, ok := setSlice(v, j.data, val)
+		if !ok {
+			log.Println(fmt.Sprintf("%v is not slice or index %d out of range, cannot set", j.data, v))
+			return j
+		}
+		j.data = data
+		if j.len() != oldLen {
+			maintainParent(j)
+		}
+	}
+	return j
+}
+
+// SetOrRemove 与Set相同，但val为nil、nil的*GoJson或值为null的*GoJson时删除key(或数组元素)，与RFC 7396 (JSON Merge Patch)一致。
+// 注意Set(key, nil)会将值设置为json的null而不是删除key，需要删除时使用SetOrRemove或Remove
+func (j *GoJson) SetOrRemove(key interface{}, val interface{}) *GoJson {
+	if value, ok := val.(*GoJson); ok && (value == nil || value.data == nil) {
+		val = nil
+	}
+	if val == nil {
+		return j.Remove(key)
+	}
+	return j.Set(key, val)
+}
+
+// SetNumber 对当前的GoJson对象对应key设置json.Number，数字按原样输出，不会经过float64损失精度。
+// n不是合法的json数字时什么都不会发生
+func (j *GoJson) SetNumber(key interface{}, n sysjson.Number) *GoJson {
+	if !isNumberString(string(n)) {
+		log.Println(fmt.Sprintf("%v is not number cannot set", n))
+		return j
+	}
+	return j.Set(key, n)
+}
+
+// Remove 删除GoJson的一个key，或数组中的一个元素。数组下标为负数时从末尾开始计算，越界时什么都不会发生
+func (j *GoJson) Remove(key interface{}) *GoJson {
+	r := j.root()
+	r.Lock()
+	defer r.Unlock()
+	if err := r.checkFrozen("remove"); err != nil {
+		log.Println(err)
+		return j
+	}
+
+	switch keyVal := key.(type) {
+	case string:
+		deleteMap(keyVal, j.data)
+	case int:
+		v, ok := removeSlice(keyVal, j.data)
+		if !ok {
+			return j
+		}
+		j.data = v
+		maintainParent(j)
+	}
+	return j
+}
+
+// Rename 将k-v结构中oldKey的值移动到newKey，newKey已存在时被覆盖，并返回自身。OrderedDict中newKey位于原来oldKey的位置。
+// oldKey不存在或当前对象不是k-v结构时什么都不会发生
+func (j *GoJson) Rename(oldKey, newKey string) *GoJson {
+	r := j.root()
+	r.Lock()
+	defer r.Unlock()
+	if err := r.checkFrozen("rename"); err != nil {
+		log.Println(err)
+		return j
+	}
+
+	jsonMap, ok := toMap(j.data)
+	if !ok {
+		return j
+	}
+	val, ok := jsonMap[oldKey]
+	if !ok || oldKey == newKey {
+		return j
+	}
+	if d, ok := j.data.(*OrderedDict); ok {
+		d.rename(oldKey, newKey)
+	} else {
+		jsonMap[newKey] = val
+		delete(jsonMap, oldKey)
+	}
+
+	maintainParent(j)
+	return j
+}
+
+// Pop 删除并返回数组的最后一个元素，数组为空或不是数组时返回的GoJson对象 IsNil将为true
+func (j *GoJson) Pop() *GoJson {
+	return j.removeAt(-1)
+}
+
+// Shift 删除并返回数组的第一个元素，数组为空或不是数组时返回的GoJson对象 IsNil将为true
+func (j *GoJson) Shift() *GoJson {
+	return j.removeAt(0)
+}
+
+func (j *GoJson) removeAt(index int) *GoJson {
+	r := j.root()
+	r.Lock()
+	defer r.Unlock()
+	if err := r.checkFrozen("remove"); err != nil {
+		log.Println(err)
+		return &GoJson{}
+	}
+
+	l, ok := toSlice(j.data)
+	if !ok || len(l) == 0 {
+		return &GoJson{}
+	}
+	if index < 0 {
+		index += len(l)
+	}
+	val := l[index]
+	v, _ := removeSlice(index, j.data)
+	j.data = v
+	maintainParent(j)
+	return &GoJson{data: val, exists: true}
+}
+
+// Merge 将other中的key-value复制到当前对象中，key相同时覆盖。两者不都是k-v结构时什么都不会发生
+func (j *GoJson) Merge(other *GoJson) *GoJson {
+	r := j.root()
+	r.Lock()
+	defer r.Unlock()
+	if err := r.checkFrozen("merge"); err != nil {
+		log.Println(err)
+		return j
+	}
+
+	if _, ok := toMap(j.data); !ok {
+		debugf("%v is not map cannot merge", j.data)
+		return j
+	}
+	if other == nil {
+		debugf("nil is not map cannot merge")
+		return j
+	}
+	src, ok := toMap(other.data)
+	if !ok {
+		debugf("%v is not map cannot merge", other.data)
+		return j
+	}
+
+	// 按输出顺序写入，当前对象为OrderedDict时新key的顺序是确定的
+	for _, key := range orderedKeys(other.data) {
+		setMap(key, j.data, src[key])
+	}
+	return j
+}
+
+// mergeDeep 将k-v结构src递归合并到dst中，dst和src都必须是k-v结构
+func mergeDeep(dst, src interface{}) {
+	dstMap, _ := toMap(dst)
+	srcMap, _ := toMap(src)
+	for _, key := range orderedKeys(src) {
+		val := srcMap[key]
+		_, srcOk := toMap(val)
+		_, dstOk := toMap(dstMap[key])
+		if srcOk && dstOk {
+			mergeDeep(dstMap[key], val)
+			continue
+		}
+		setMap(key, dst, val)
+	}
+}
+
+// MergeDeep 与Merge类似，但两边都是k-v结构的子节点会递归合并，而不是整个覆盖
+func (j *GoJson) MergeDeep(other *GoJson) *GoJson {
+	r := j.root()
+	r.Lock()
+	defer r.Unlock()
+	if err := r.checkFrozen("merge"); err != nil {
+		log.Println(err)
+		return j
+	}
+
+	if _, ok := toMap(j.data); !ok {
+		debugf("%v is not map cannot merge", j.data)
+		return j
+	}
+	if other == nil {
+		debugf("nil is not map cannot merge")
+		return j
+	}
+	if _, ok := toMap(other.data); !ok {
+		debugf("%v is not map cannot merge", other.data)
+		return j
+	}
+
+	mergeDeep(j.data, other.data)
+	return j
+}
+
+// maxRatLen、maxRatExponent 限制toRat精确处理的json.Number的长度和指数。big.Rat.SetString的开销随两者增长，
+// 如 1e9999999 会分配巨大的整数，不能直接用于不可信的输入
+const (
+	maxRatLen      = 1000
+	maxRatExponent = 1000
+)
+
+// numberInRatRange 判断n的长度和指数是否在toRat精确处理的范围内
+func numberInRatRange(n string) bool {
+	if len(n) > maxRatLen {
+		return false
+	}
+	i := strings.IndexAny(n, "eE")
+	if i < 0 {
+		return true
+	}
+	exp, err := strconv.Atoi(n[i+1:])
+	return err == nil && exp >= -maxRatExponent && exp <= maxRatExponent
+}
+
+// toRat 将各类数字转换为big.Rat用于精确比较，非数字及超出maxRatLen、maxRatExponent的json.Number返回false
+func toRat(v interface{}) (*big.Rat, bool) {
+	switch n := v.(type) {
+	case sysjson.Number:
+		if !numberInRatRange(string(n)) {
+			return nil, false
+		}
+		return new(big.Rat).SetString(string(n))
+	case int:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case int8:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case int16:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case int32:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case int64:
+		return new(big.Rat).SetInt64(n), true
+	case uint:
+		return new(big.Rat).SetInt(new(big.Int).SetUint64(uint64(n))), true
+	case uint8:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case uint16:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case uint32:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case uint64:
+		return new(big.Rat).SetInt(new(big.Int).SetUint64(n)), true
+	case float32:
+		if r := new(big.Rat).SetFloat64(float64(n)); r != nil {
+			return r, true
+		}
+	case float64:
+		if r := new(big.Rat).SetFloat64(n); r != nil {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// compareNumbers 比较两个数字的大小，非数字时返回false。toRat能处理时精确比较，否则按float64比较，
+// 此时超出float64范围的数字按±Inf或0处理
+func compareNumbers(a, b interface{}) (int, bool) {
+	if aRat, ok := toRat(a); ok {
+		if bRat, ok := toRat(b); ok {
+			return aRat.Cmp(bRat), true
+		}
+	}
+	aFloat, aOk := numberFloat(a)
+	bFloat, bOk := numberFloat(b)
+	if !aOk || !bOk {
+		return 0, false
+	}
+	switch {
+	case aFloat < bFloat:
+		return -1, true
+	case aFloat > bFloat:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
+// numberFloat 将数字转换为float64，json.Number超出范围时返回±Inf或0，非数字及NaN返回false
+func numberFloat(v interface{}) (float64, bool) {
+	if kindOf(v) != KindNumber {
+		return 0, false
+	}
+	if n, ok := v.(sysjson.Number); ok {
+		f, err := strconv.ParseFloat(string(n), 64)
+		if err != nil && !errors.Is(err, strconv.ErrRange) {
+			return 0, false
+		}
+		return f, true
+	}
+	f, err := ToFloat64(v)
+	return f, err == nil && !math.IsNaN(f)
+}
+
+func equalValue(a, b interface{}) bool {
+	aMap, aIsMap := toMap(a)
+	bMap, bIsMap := toMap(b)
+	if aIsMap || bIsMap {
+		if !aIsMap || !bIsMap || len(aMap) != len(bMap) {
+			return false
+		}
+		for key, aVal := range aMap {
+			bVal, ok := bMap[key]
+			if !ok || !equalValue(aVal, bVal) {
+				return false
+			}
+		}
+		return true
+	}
+
+	aSlice, aIsSlice := toSlice(a)
+	bSlice, bIsSlice := toSlice(b)
+	if aIsSlice || bIsSlice {
+		if !aIsSlice || !bIsSlice || len(aSlice) != len(bSlice) {
+			return false
+		}
+		for i := range aSlice {
+			if !equalValue(aSlice[i], bSlice[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if kindOf(a) == KindNumber || kindOf(b) == KindNumber {
+		c, ok := compareNumbers(a, b)
+		return ok && c == 0
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// Equals 深度比较两个GoJson对象的数据是否相同。map与key顺序无关，数组与顺序有关，
+// json.Number、int、float64等表示同一数值时视为相等
+func (j *GoJson) Equals(other *GoJson) bool {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	if other == nil {
+		return j.data == nil
+	}
+	return equalValue(j.data, other.data)
+}
+
+// Value 返回GoJson对象的真实数据
+func (j *GoJson) Value() interface{} {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	v := j.data
+	return v
+}
+
+// Len 返回数组对象的长度，如果源数据不是数组，则返回0
+func (j *GoJson) Len() int {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	return j.len()
+}
+
+func (j *GoJson) len() int {
+	switch v := j.data.(type) {
+	case []interface{}:
+		return len(v)
+	case List:
+		return len(v)
+	default:
+		return 0
+	}
+}
+
+// String方法返回GoJson对象的字符串值
+func (j *GoJson) String() string {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	return j.string()
+}
+
+// string 不加锁的String，供已持有锁的方法调用
+func (j *GoJson) string() string {
+	if j.data == nil {
+		return ""
+	}
+	switch j.data.(type) {
+	case map[string]interface{}, []interface{}, Dict, *OrderedDict, List:
+		buffer := &bytes.Buffer{}
+		encoder := json.NewEncoder(buffer)
+		encoder.SetEscapeHTML(EscapeHTML)
+		err := encoder.Encode(j.data)
+		if err != nil {
+			log.Println("convert to String is error", err)
+			return ""
+		}
+		return buffer.String()
+	default:
+		return ToString(j.data)
+	}
+}
+
+// Bytes 返回GoJson对象的bytes值
+func (j *GoJson) Bytes() []byte {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	if j.data == nil {
+		return []byte("")
+	}
+	switch j.data.(type) {
+	case map[string]interface{}, []interface{}, Dict, *OrderedDict, List:
+		result, err := marshal(j.data)
+		if err != nil {
+			log.Println("convert to bytes is error", err)
+			return []byte("")
+		}
+		return result
+	default:
+		return []byte(ToString(j.data))
+	}
+}
+
+// appendStreams 供AppendBytes使用的stream，下标1为不转义HTML字符的配置。
+// 编码时stream直接使用调用方的dst作为buffer，不能归还到jsoniter共用的池中，否则池中的stream会失去已分配的buffer，
+// 之后Marshal以及排序map key时借用的stream都要重新分配
+var appendStreams = [2]sync.Pool{
+	{New: func() interface{} { return jsoniterator.NewStream(json, nil, 0) }},
+	{New: func() interface{} { return jsoniterator.NewStream(jsonNoEscapeHTML, nil, 0) }},
+}
+
+// writeValue 将val写入stream，结果与marshal相同，escapeHTML须与stream的配置一致。
+// map[string]interface{}和[]interface{}逐个元素写出，不经过jsoniter按key排序时借用的额外stream，其余类型使用WriteVal
+func writeValue(stream *jsoniterator.Stream, val interface{}, escapeHTML bool) {
+	var m map[string]interface{}
+	switch v := val.(type) {
+	case map[string]interface{}:
+		m = v
+	case Dict:
+		m = v
+	case []interface{}:
+		writeValues(stream, v, escapeHTML)
+		return
+	case List:
+		writeValues(stream, v, escapeHTML)
+		return
+	default:
+		stream.WriteVal(val)
+		return
+	}
+	if m == nil {
+		stream.WriteNil()
+		return
+	}
+
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	stream.WriteObjectStart()
+	for i, key := range keys {
+		if i > 0 {
+			stream.WriteMore()
+		}
+		// WriteObjectField不会转义HTML字符，与marshal不一致
+		if escapeHTML {
+			stream.WriteStringWithHTMLEscaped(key)
+		} else {
+			stream.WriteString(key)
+		}
+		stream.WriteRaw(":")
+		writeValue(stream, m[key], escapeHTML)
+	}
+	stream.WriteObjectEnd()
+}
+
+// writeValues 将数组逐个元素写入stream
+func writeValues(stream *jsoniterator.Stream, l []interface{}, escapeHTML bool) {
+	if l == nil {
+		stream.WriteNil()
+		return
+	}
+	stream.WriteArrayStart()
+	for i, item := range l {
+		if i > 0 {
+			stream.WriteMore()
+		}
+		writeValue(stream, item, escapeHTML)
+	}
+	stream.WriteArrayEnd()
+}
+
+// AppendBytes 将Bytes的结果追加到dst之后并返回，类似strconv.AppendInt。dst容量足够时不会重新分配，
+// 可在循环中复用同一个buffer输出大量数据。数字和HTML转义的处理与Bytes相同
+func (j *GoJson) AppendBytes(dst []byte) ([]byte, error) {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	if j.data == nil {
+		return dst, nil
+	}
+	switch j.data.(type) {
+	case map[string]interface{}, []interface{}, Dict, *OrderedDict, List:
+		// 直接编码到dst中，不经过中间的[]byte
+		escapeHTML := EscapeHTML
+		pool := &appendStreams[0]
+		if !escapeHTML {
+			pool = &appendStreams[1]
+		}
+		stream := pool.Get().(*jsoniterator.Stream)
+		stream.SetBuffer(dst)
+		writeValue(stream, j.data, escapeHTML)
+		result, err := stream.Buffer(), stream.Error
+		// 放回前解除对dst的引用，避免之后的编码写入调用方的buffer
+		stream.SetBuffer(nil)
+		stream.Error = nil
+		pool.Put(stream)
+		if err != nil {
+			return dst, err
+		}
+		return result, nil
+	default:
+		return append(dst, ToString(j.data)...), nil
+	}
+}
+
+// countWriter 记录写入的字节数
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// WriteTo 将json直接编码写入w，返回写入的字节数，实现io.WriterTo。
+// 与Bytes不同，字符串等标量也按json格式输出，null输出为null，末尾带换行符
+func (j *GoJson) WriteTo(w io.Writer) (int64, error) {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	cw := &countWriter{w: w}
+	encoder := json.NewEncoder(cw)
+	encoder.SetEscapeHTML(EscapeHTML)
+	err := encoder.Encode(j.data)
+	return cw.n, err
+}
+
+// countReader 记录读取的字节数
+type countReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// ReadFrom 从r解析一个json值并替换当前数据，返回从r读取的字节数，实现io.ReaderFrom。
+// 解析失败时当前数据保持不变。之前通过Get、Index得到的子节点不会再与之关联
+func (j *GoJson) ReadFrom(reader io.Reader) (int64, error) {
+	cr := &countReader{r: reader}
+	js, err := decodeJson(cr)
+	if err != nil {
+		return cr.n, fmt.Errorf("js解析失败：%v", err)
+	}
+
+	r := j.root()
+	r.Lock()
+	defer r.Unlock()
+	if err := r.checkFrozen("read"); err != nil {
+		return cr.n, err
+	}
+
+	j.data = js.data
+	maintainParent(j)
+	return cr.n, nil
+}
+
+// MarshalJSON 实现json.Marshaler，使GoJson可以作为结构体字段参与json编码。
+// 与Bytes不同，null和字符串会按json格式输出
+func (j *GoJson) MarshalJSON() ([]byte, error) {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	return marshal(j.data)
+}
+
+// UnmarshalJSON 实现json.Unmarshaler，解析b并替换当前的数据
+func (j *GoJson) UnmarshalJSON(b []byte) error {
+	js, err := decodeJsonAll(bytes.NewReader(b), true)
+	if err != nil {
+		return err
+	}
+
+	r := j.root()
+	r.Lock()
+	defer r.Unlock()
+	if err := r.checkFrozen("unmarshal"); err != nil {
+		return err
+	}
+
+	j.data = js.data
+	maintainParent(j)
+	return nil
+}
+
+// PrettyBytes 返回按indent缩进格式化后的bytes值，map的key按字典序输出
+func (j *GoJson) PrettyBytes(indent string) []byte {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	if j.data == nil {
+		return []byte("")
+	}
+	result, err := marshal(j.data)
+	if err != nil {
+		log.Println("convert to bytes is error", err)
+		return []byte("")
+	}
+	buffer := &bytes.Buffer{}
+	if err := sysjson.Indent(buffer, result, "", indent); err != nil {
+		log.Println("indent json is error", err)
+		return []byte("")
+	}
+	return buffer.Bytes()
+}
+
+// Pretty 返回按indent缩进格式化后的字符串，如 Pretty("  ")、Pretty("\t")
+func (j *GoJson) Pretty(indent string) string {
+	return string(j.PrettyBytes(indent))
+}
+
+// normalizeNumber 返回数字的规范形式：整数输出为不带小数点和指数的十进制，其他数字按float64输出，
+// 如 1.0、1e0 都输出为 1
+func normalizeNumber(rat *big.Rat) string {
+	if rat.IsInt() {
+		return rat.Num().String()
+	}
+	f, _ := rat.Float64()
+	return formatFloat(f, 64)
+}
+
+// writeSorted 将val按key有序写入buffer，normalizeNumbers为true时数字按normalizeNumber输出
+func writeSorted(buffer *bytes.Buffer, val interface{}, normalizeNumbers bool) error {
+	var m map[string]interface{}
+	var l []interface{}
+	switch v := val.(type) {
+	case Dict:
+		m = v
+	case *OrderedDict:
+		m = v.values
+	case map[string]interface{}:
+		m = v
+	case List:
+		l = v
+	case []interface{}:
+		l = v
+	default:
+		if rat, ok := toRat(v); ok && normalizeNumbers {
+			buffer.WriteString(normalizeNumber(rat))
+			return nil
+		}
+		result, err := marshal(v)
+		if err != nil {
+			return err
+		}
+		buffer.Write(result)
+		return nil
+	}
+
+	if m != nil {
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		buffer.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			keyBytes, err := marshal(key)
+			if err != nil {
+				return err
+			}
+			buffer.Write(keyBytes)
+			buffer.WriteByte(':')
+			if err := writeSorted(buffer, m[key], normalizeNumbers); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte('}')
+		return nil
+	}
+
+	if l == nil {
+		buffer.WriteString("null")
+		return nil
+	}
+	buffer.WriteByte('[')
+	for i, item := range l {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		if err := writeSorted(buffer, item, normalizeNumbers); err != nil {
+			return err
+		}
+	}
+	buffer.WriteByte(']')
+	return nil
+}
+
+// MarshalSorted 返回json编码，所有层级map的key都按字典序输出，不依赖json配置，输出稳定可用于比较
+func (j *GoJson) MarshalSorted() ([]byte, error) {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	buffer := &bytes.Buffer{}
+	if err := writeSorted(buffer, j.data, false); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// hasSpace 判断json编码中字符串以外的位置是否有空白。marshal的结果本身是紧凑的，
+// 只有json.RawMessage和实现了json.Marshaler的值会原样输出其中的空白
+func hasSpace(b []byte) bool {
+	inString := false
+	for i := 0; i < len(b); i++ {
+		if inString {
+			switch b[i] {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+		switch b[i] {
+		case '"':
+			inString = true
+		case ' ', '\t', '\r', '\n':
+			return true
+		}
+	}
+	return false
+}
+
+// Compact 返回紧凑的json编码，不含任何多余的空白。json.Number按原样输出，不会转换为科学计数法。
+// 编码失败时返回空bytes
+func (j *GoJson) Compact() []byte {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	result, err := marshal(j.data)
+	if err != nil {
+		log.Println("convert to bytes is error", err)
+		return []byte("")
+	}
+	if !hasSpace(result) {
+		return result
+	}
+	buffer := &bytes.Buffer{}
+	if err := sysjson.Compact(buffer, result); err != nil {
+		log.Println("compact json is error", err)
+		return []byte("")
+	}
+	return buffer.Bytes()
+}
+
+// serializedSize 计算val紧凑json编码的字节数，map和数组逐个元素累加，只有标量会被实际编码
+func serializedSize(val interface{}) (int, error) {
+	// 值为nil的map和数组编码为null
+	switch v := val.(type) {
+	case map[string]interface{}:
+		if v == nil {
+			return 4, nil
+		}
+	case Dict:
+		if v == nil {
+			return 4, nil
+		}
+	case *OrderedDict:
+		if v == nil {
+			return 4, nil
+		}
+	case []interface{}:
+		if v == nil {
+			return 4, nil
+		}
+	case List:
+		if v == nil {
+			return 4, nil
+		}
+	}
+	if m, ok := toMap(val); ok {
+		size := 2
+		for key, child := range m {
+			keyBytes, err := marshal(key)
+			if err != nil {
+				return 0, err
+			}
+			childSize, err := serializedSize(child)
+			if err != nil {
+				return 0, err
+			}
+			size += len(keyBytes) + 1 + childSize
+		}
+		if len(m) > 1 {
+			size += len(m) - 1
+		}
+		return size, nil
+	}
+	if l, ok := toSlice(val); ok {
+		size := 2
+		for _, child := range l {
+			childSize, err := serializedSize(child)
+			if err != nil {
+				return 0, err
+			}
+			size += childSize
+		}
+		if len(l) > 1 {
+			size += len(l) - 1
+		}
+		return size, nil
+	}
+	switch v := val.(type) {
+	case nil:
+		return 4, nil
+	case bool:
+		if v {
+			return 4, nil
+		}
+		return 5, nil
+	case sysjson.Number:
+		return len(v), nil
+	}
+	result, err := marshal(val)
+	if err != nil {
+		return 0, err
+	}
+	return len(result), nil
+}
+
+// SerializedSize 返回紧凑json编码(与MarshalJSON、Compact的输出相同)的字节数，可用于在输出前检查大小。
+// 遍历整棵树逐个累加，耗时与节点数成正比，但不会生成完整的json，只有字符串等标量会被单独编码。编码失败时返回-1
+func (j *GoJson) SerializedSize() int {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	size, err := serializedSize(j.data)
+	if err != nil {
+		log.Println("convert to bytes is error", err)
+		return -1
+	}
+	return size
+}
+
+// Canonical 返回规范化的json编码：所有层级map的key按字典序输出，数字按数值规范化(如 1.0 输出为 1)，
+// 且不含任何多余的空白。相同内容的对象得到的结果相同，可用于比较或计算hash
+func (j *GoJson) Canonical() ([]byte, error) {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	sorted := &bytes.Buffer{}
+	if err := writeSorted(sorted, j.data, true); err != nil {
+		return nil, err
+	}
+	buffer := &bytes.Buffer{}
+	if err := sysjson.Compact(buffer, sorted.Bytes()); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// Hash 返回Canonical编码的SHA-256摘要(十六进制)，key顺序或数字写法不同但内容相同的对象得到的结果相同
+func (j *GoJson) Hash() (string, error) {
+	canonical, err := j.Canonical()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Int 返回GoJson对象的源数据, 并尝试转换为int
+func (j *GoJson) Int() (int, error) {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	v := j.data
+	if v == nil {
+		return 0, errors.New(fmt.Sprintf("%v is not int", j.data))
+	}
+	return ToInt(v)
+}
+
+// Int64 返回GoJson对象的源数据, 并尝试转换为int64
+func (j *GoJson) Int64() (int64, error) {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	v := j.data
+	if v == nil {
+		return 0, errors.New(fmt.Sprintf("%v is not int", j.data))
+	}
+	return ToInt64(v)
+}
+
+// Uint64 返回GoJson对象的源数据, 并尝试转换为uint64
+func (j *GoJson) Uint64() (uint64, error) {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	v := j.data
+	if v == nil {
+		return 0, errors.New(fmt.Sprintf("%v is not uint64", j.data))
+	}
+	return ToUint64(v)
+}
+
+// Float64 返回GoJson对象的源数据, 并尝试转换为float64
+func (j *GoJson) Float64() (float64, error) {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	v := j.data
+	if v == nil {
+		return 0, errors.New(fmt.Sprintf("%v is not float64", j.data))
+	}
+	return ToFloat64(v)
+}
+
+// Number 返回GoJson对象的源数据对应的json.Number，解析得到的数字原样返回，不经过float64，
+// 通过Set设置的int、float等数字会转换为等价的json.Number
+func (j *GoJson) Number() (sysjson.Number, error) {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	return ToNumber(j.data)
+}
+
+// Bool 返回GoJson对象的源数据, 并尝试转换为bool
+func (j *GoJson) Bool() (bool, error) {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	return ToBool(j.data)
+}
+
+// Array 返回数组对象的源数据，如果源数据不是数组，则返回error
+func (j *GoJson) Array() ([]interface{}, error) {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	switch v := j.data.(type) {
+	case List:
+		return v, nil
+	case []interface{}:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("%v is not array", j.data)
+	}
+}
+
+// IsString 如果json值为string, 则返回true, 否则false
+func (j *GoJson) IsString() bool {
+	return j.Kind() == KindString
+}
+
+// IsNumber 如果json值为数字(json.Number、int、float64等), 则返回true, 否则false
+func (j *GoJson) IsNumber() bool {
+	return j.Kind() == KindNumber
+}
+
+// IsBool 如果json值为bool, 则返回true, 否则false
+func (j *GoJson) IsBool() bool {
+	return j.Kind() == KindBool
+}
+
+// IsNull 如果json值为null, 则返回true, 否则false。与IsNil相同
+func (j *GoJson) IsNull() bool {
+	return j.Kind() == KindNull
+}
+
+// Type 返回json值的类型, see: fmt.Sprintf("%T", foo)。需要json类型时使用Kind
+func (j *GoJson) Type() string {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	return fmt.Sprintf("%T", j.data)
+}
+
+// Kind json值的类型
+type Kind string
+
+const (
+	KindObject  Kind = "object"
+	KindArray   Kind = "array"
+	KindString  Kind = "string"
+	KindNumber  Kind = "number"
+	KindBool    Kind = "bool"
+	KindNull    Kind = "null"
+	KindUnknown Kind = "unknown" // 通过Set等方式放入的非json类型，如结构体
+)
+
+func kindOf(data interface{}) Kind {
+	switch data.(type) {
+	case nil:
+		return KindNull
+	case map[string]interface{}, Dict, *OrderedDict:
+		return KindObject
+	case []interface{}, List:
+		return KindArray
+	case string:
+		return KindString
+	case bool:
+		return KindBool
+	case sysjson.Number, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return KindNumber
+	default:
+		return KindUnknown
+	}
+}
+
+// Kind 返回json值的类型：object、array、string、number、bool、null
+func (j *GoJson) Kind() Kind {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	return kindOf(j.data)
+}
+
+// snapshotMap 在读锁内复制k-v结构的key和值，sorted为true时按key的字典序，否则*OrderedDict按插入顺序。
+// 带回调的方法在锁外使用复制的结果，回调中可以调用同一棵树上的方法
+func (j *GoJson) snapshotMap(sorted bool) ([]string, []interface{}, bool) {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	jsonMap, ok := toMap(j.data)
+	if !ok {
+		return nil, nil, false
+	}
+	var keys []string
+	if ordered, isOrdered := j.data.(*OrderedDict); isOrdered {
+		keys = ordered.Keys()
+	} else {
+		keys = make([]string, 0, len(jsonMap))
+		for key := range jsonMap {
+			keys = append(keys, key)
+		}
+	}
+	if sorted {
+		sort.Strings(keys)
+	}
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		values[i] = jsonMap[key]
+	}
+	return keys, values, true
+}
+
+// snapshotSlice 在读锁内复制数组的元素，用法与snapshotMap相同
+func (j *GoJson) snapshotSlice() ([]interface{}, bool) {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	l, ok := toSlice(j.data)
+	if !ok {
+		return nil, false
+	}
+	return append(make([]interface{}, 0, len(l)), l...), true
+}
+
+// RangeMap 遍历kv结构， 传入的函数用于处理遍历。如果这个函数返回false，遍历将立刻结束
+func (j *GoJson) RangeMap(f func(key string, val interface{}) bool) error {
+	keys, values, ok := j.snapshotMap(false)
+	if !ok {
+		return fmt.Errorf("%v is not map", j.Value())
+	}
+	for i, key := range keys {
+		if !f(key, values[i]) {
+			break
+		}
+	}
+	return nil
+}
+
+// RangeMapSorted 与RangeMap相同，但按key的字典序遍历，结果稳定。如果传入的函数返回false，遍历将立刻结束
+func (j *GoJson) RangeMapSorted(f func(key string, val interface{}) bool) error {
+	keys, values, ok := j.snapshotMap(true)
+	if !ok {
+		return fmt.Errorf("%v is not map", j.Value())
+	}
+	for i, key := range keys {
+		if !f(key, values[i]) {
+			break
+		}
+	}
+	return nil
+}
+
+// RangeSlice 遍历数组结构， 传入的函数用于处理遍历。如果这个函数返回false，遍历将立刻结束
+func (j *GoJson) RangeSlice(f func(index int, val interface{}) bool) error {
+	l, ok := j.snapshotSlice()
+	if !ok {
+		return fmt.Errorf("%v is not Slice", j.Value())
+	}
+	for key, val := range l {
+		if !f(key, val) {
+			break
+		}
+	}
+	return nil
+}
+
+// Filter 返回一个新的数组，只包含pred返回true的元素。源数据不是数组时返回的GoJson对象 IsNil将为true
+func (j *GoJson) Filter(pred func(index int, val interface{}) bool) *GoJson {
+	l, ok := j.snapshotSlice()
+	if !ok {
+		return &GoJson{}
+	}
+	result := make([]interface{}, 0)
+	for i, val := range l {
+		if pred(i, val) {
+			result = append(result, val)
+		}
+	}
+	return NewJsonFromData(result)
+}
+
+// Find 返回数组中第一个pred返回true的元素，找到后立刻停止遍历。
+// 没有匹配的元素或源数据不是数组时返回的GoJson对象 IsNil将为true，且不与当前对象关联
+func (j *GoJson) Find(pred func(index int, val interface{}) bool) *GoJson {
+	index := j.FindIndex(pred)
+	if index < 0 {
+		return &GoJson{}
+	}
+	return j.Index(index)
+}
+
+// FindIndex 返回数组中第一个pred返回true的元素的下标，找到后立刻停止遍历。
+// 没有匹配的元素或源数据不是数组时返回-1
+func (j *GoJson) FindIndex(pred func(index int, val interface{}) bool) int {
+	l, ok := j.snapshotSlice()
+	if !ok {
+		return -1
+	}
+	for i, val := range l {
+		if pred(i, val) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Update 将数组中pred返回true的元素原地替换为fn的返回值，返回替换的个数。当json不为slice或已冻结时返回0，什么都不会发生。
+// pred和fn在锁外对元素的副本调用，之后在写锁内按下标写回，期间数组长度被其他goroutine改变时放弃写回并返回0
+func (j *GoJson) Update(pred func(index int, val interface{}) bool, fn func(val interface{}) interface{}) int {
+	l, ok := j.snapshotSlice()
+	if !ok {
+		return 0
+	}
+	indexes := make([]int, 0)
+	values := make([]interface{}, 0)
+	for i, val := range l {
+		if !pred(i, val) {
+			continue
+		}
+		updated := fn(val)
+		if value, ok := updated.(*GoJson); ok {
+			updated = value.Value()
+		}
+		indexes = append(indexes, i)
+		values = append(values, updated)
+	}
+	if len(indexes) == 0 {
+		return 0
+	}
+
+	r := j.root()
+	r.Lock()
+	defer r.Unlock()
+	if err := r.checkFrozen("update"); err != nil {
+		log.Println(err)
+		return 0
+	}
+	current, ok := toSlice(j.data)
+	if !ok || len(current) != len(l) {
+		return 0
+	}
+	for i, index := range indexes {
+		current[index] = values[i]
+	}
+	maintainParent(j)
+	return len(indexes)
+}
+
+// GroupBy 将元素为k-v结构的数组按key对应的值分组，返回 值 => 元素数组 的k-v结构，组内保持原有顺序。
+// 值通过ToString转换为分组的key，null为"null"，map和数组编码为json。缺少该key的元素被忽略。
+// 源数据不是数组时返回的GoJson对象 IsNil将为true
+func (j *GoJson) GroupBy(key string) *GoJson {
+	return j.groupBy(key, "", false)
+}
+
+// GroupByOr 与GroupBy相同，但缺少该key的元素(包括不是k-v结构的元素)放入bucket分组
+func (j *GoJson) GroupByOr(key string, bucket string) *GoJson {
+	return j.groupBy(key, bucket, true)
+}
+
+func (j *GoJson) groupBy(key string, bucket string, keepMissing bool) *GoJson {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	l, ok := toSlice(j.data)
+	if !ok {
+		return &GoJson{}
+	}
+	result := make(map[string]interface{})
+	for _, item := range l {
+		val, ok := lookupMap(key, item)
+		var group string
+		switch {
+		case !ok && !keepMissing:
+			continue
+		case !ok:
+			group = bucket
+		case val == nil:
+			group = "null"
+		default:
+			if _, isMap := toMap(val); isMap {
+				b, _ := marshal(val)
+				group = string(b)
+			} else if _, isSlice := toSlice(val); isSlice {
+				b, _ := marshal(val)
+				group = string(b)
+			} else {
+				group = ToString(val)
+			}
+		}
+		items, _ := result[group].([]interface{})
+		result[group] = append(items, item)
+	}
+	return NewJsonFromData(result)
+}
+
+// MapSlice 返回一个新的数组，每个元素为fn转换后的值。源数据不是数组时返回的GoJson对象 IsNil将为true
+func (j *GoJson) MapSlice(fn func(index int, val interface{}) interface{}) *GoJson {
+	l, ok := j.snapshotSlice()
+	if !ok {
+		return &GoJson{}
+	}
+	result := make([]interface{}, 0, len(l))
+	for i, val := range l {
+		mapped := fn(i, val)
+		if value, ok := mapped.(*GoJson); ok {
+			mapped = value.data
+		}
+		result = append(result, mapped)
+	}
+	return NewJsonFromData(result)
+}
+
+// Concat 返回一个新的数组，依次包含当前数组和other中的元素，other可以是*GoJson、[]interface{}或List。
+// other为nil或值为null时视为空数组。任意一方不是数组时返回的GoJson对象 IsNil将为true
+func (j *GoJson) Concat(other interface{}) *GoJson {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	if value, ok := other.(*GoJson); ok {
+		if value == nil {
+			other = nil
+		} else {
+			other = value.data
+		}
+	}
+	if other == nil {
+		other = []interface{}{}
+	}
+	l, ok := toSlice(j.data)
+	if !ok {
+		log.Println(fmt.Sprintf("%v is not slice cannot concat", j.data))
+		return &GoJson{}
+	}
+	otherList, ok := toSlice(other)
+	if !ok {
+		log.Println(fmt.Sprintf("%v is not slice cannot concat", other))
+		return &GoJson{}
+	}
+
+	result := make([]interface{}, 0, len(l)+len(otherList))
+	result = append(result, l...)
+	result = append(result, otherList...)
+	return NewJsonFromData(result)
+}
+
+// valueKey 返回用于比较的字符串，值相同时结果相同，normalizeNumbers为true时数字按数值比较
+func valueKey(val interface{}, normalizeNumbers bool) string {
+	if rat, ok := toRat(val); ok && normalizeNumbers {
+		return "#" + rat.RatString()
+	}
+	if b, err := marshal(val); err == nil {
+		return string(b)
+	}
+	return fmt.Sprintf("%T:%v", val, val)
+}
+
+// Unique 返回一个新的数组，去除重复的元素，保留第一次出现的顺序。元素按json序列化结果比较，
+// k-v结构的key有序，因此key顺序不同的对象视为相同。normalizeNumbers为true时数字按数值比较，
+// 如 1 与 1.0 视为相同。源数据不是数组时返回的GoJson对象 IsNil将为true
+func (j *GoJson) Unique(normalizeNumbers bool) *GoJson {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	l, ok := toSlice(j.data)
+	if !ok {
+		return &GoJson{}
+	}
+
+	seen := make(map[string]bool, len(l))
+	result := make([]interface{}, 0, len(l))
+	for _, val := range l {
+		key := valueKey(val, normalizeNumbers)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, val)
+	}
+	return NewJsonFromData(result)
+}
+
+// MergeArrayByKey 将元素为k-v结构的数组other按idKey合并到当前数组中并返回自身：idKey的值相同的元素被other中的元素替换，
+// other中没有匹配的元素追加到末尾，当前数组原有的顺序保持不变。idKey的值按数值比较数字，如 1 与 1.0 视为相同。
+// 两者不都是数组或other为nil时什么都不会发生
+func (j *GoJson) MergeArrayByKey(other *GoJson, idKey string) *GoJson {
+	r := j.root()
+	r.Lock()
+	defer r.Unlock()
+	if err := r.checkFrozen("merge"); err != nil {
+		log.Println(err)
+		return j
+	}
+
+	l, ok := toSlice(j.data)
+	if !ok {
+		log.Println(fmt.Sprintf("%v is not slice cannot merge", j.data))
+		return j
+	}
+	if other == nil {
+		log.Println("nil is not slice cannot merge")
+		return j
+	}
+	otherList, ok := toSlice(other.data)
+	if !ok {
+		log.Println(fmt.Sprintf("%v is not slice cannot merge", other.data))
+		return j
+	}
+
+	positions := make(map[string][]int, len(l))
+	for i, item := range l {
+		if id, ok := lookupMap(idKey, item); ok {
+			key := valueKey(id, true)
+			positions[key] = append(positions[key], i)
+		}
+	}
+	for _, item := range otherList {
+		id, ok := lookupMap(idKey, item)
+		if !ok {
+			l = append(l, item)
+			continue
+		}
+		key := valueKey(id, true)
+		if indexes, ok := positions[key]; ok {
+			for _, index := range indexes {
+				l[index] = item
+			}
+			continue
+		}
+		positions[key] = []int{len(l)}
+		l = append(l, item)
+	}
+
+	if _, ok := j.data.(List); ok {
+		j.data = List(l)
+	} else {
+		j.data = l
+	}
+	maintainParent(j)
+	return j
+}
+
+// Sort 使用less对数组进行原地稳定排序并返回自身，当json不为slice，将直接返回自身。
+// less在锁外对元素的副本调用，之后在写锁内写回，期间数组长度被其他goroutine改变时放弃写回
+func (j *GoJson) Sort(less func(a, b interface{}) bool) *GoJson {
+	l, ok := j.snapshotSlice()
+	if !ok {
+		log.Println(fmt.Sprintf("%v is not slice cannot sort", j.Value()))
+		return j
+	}
+	sort.SliceStable(l, func(a, b int) bool {
+		return less(l[a], l[b])
+	})
+
+	r := j.root()
+	r.Lock()
+	defer r.Unlock()
+	if err := r.checkFrozen("sort"); err != nil {
+		log.Println(err)
+		return j
+	}
+	current, ok := toSlice(j.data)
+	if !ok || len(current) != len(l) {
+		return j
+	}
+	copy(current, l)
+
+	maintainParent(j)
+	return j
+}
+
+// sortLocked 在写锁内使用less对数组原地稳定排序，供比较函数不访问树的SortByKey等方法使用
+func (j *GoJson) sortLocked(less func(a, b interface{}) bool) *GoJson {
+	r := j.root()
+	r.Lock()
+	defer r.Unlock()
+	if err := r.checkFrozen("sort"); err != nil {
+		log.Println(err)
+		return j
+	}
+
+	l, ok := toSlice(j.data)
+	if !ok {
+		log.Println(fmt.Sprintf("%v is not slice cannot sort", j.data))
+		return j
+	}
+	sort.SliceStable(l, func(a, b int) bool {
+		return less(l[a], l[b])
+	})
+
+	maintainParent(j)
+	return j
+}
+
+// Reverse 将数组原地倒序并返回自身，当json不为slice，什么都不会发生
+func (j *GoJson) Reverse() *GoJson {
+	r := j.root()
+	r.Lock()
+	defer r.Unlock()
+	if err := r.checkFrozen("reverse"); err != nil {
+		log.Println(err)
+		return j
+	}
+
+	l, ok := toSlice(j.data)
+	if !ok {
+		return j
+	}
+	for a, b := 0, len(l)-1; a < b; a, b = a+1, b-1 {
+		l[a], l[b] = l[b], l[a]
+	}
+
+	maintainParent(j)
+	return j
+}
+
+// kindOrder compareValues中不同类型之间的顺序
+var kindOrder = map[Kind]int{KindNumber: 0, KindString: 1, KindBool: 2, KindArray: 3, KindObject: 4, KindUnknown: 5, KindNull: 6}
+
+// compareValues 比较两个值的大小：数字按数值、字符串按字典序比较，类型不同时按类型排序，null和不存在的值排在最后
+func compareValues(a, b interface{}) int {
+	aKind, bKind := kindOf(a), kindOf(b)
+	if aKind != bKind {
+		return kindOrder[aKind] - kindOrder[bKind]
+	}
+
+	switch aKind {
+	case KindNumber:
+		c, _ := compareNumbers(a, b)
+		return c
+	case KindString:
+		return strings.Compare(a.(string), b.(string))
+	case KindBool:
+		if a.(bool) == b.(bool) {
+			return 0
+		}
+		if !a.(bool) {
+			return -1
+		}
+		return 1
+	default:
+		return 0
+	}
+}
+
+// SortByKey 对元素为k-v结构的数组，按key对应的值升序稳定排序。数字按数值比较，缺少该key的元素排在最后
+func (j *GoJson) SortByKey(key string) *GoJson {
+	return j.sortLocked(func(a, b interface{}) bool {
+		aVal, _ := getMap(key, a)
+		bVal, _ := getMap(key, b)
+		return compareValues(aVal, bVal) < 0
+	})
+}
+
+// SortStrings 将数组元素转换为字符串后按字典序升序排序
+func (j *GoJson) SortStrings() *GoJson {
+	return j.sortLocked(func(a, b interface{}) bool {
+		return ToString(a) < ToString(b)
+	})
+}
+
+// SortNumbers 将数组元素按数值升序排序，不是数字的元素排在最后
+func (j *GoJson) SortNumbers() *GoJson {
+	return j.sortLocked(func(a, b interface{}) bool {
+		return compareValues(a, b) < 0
+	})
+}
+
+// CountNodes 返回树中值的总数，包括根节点、所有容器和标量。使用显式栈遍历，不会因嵌套过深而栈溢出
+func (j *GoJson) CountNodes() int {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	count := 0
+	stack := []interface{}{j.data}
+	for len(stack) > 0 {
+		val := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		count++
+		if m, ok := toMap(val); ok {
+			for _, child := range m {
+				stack = append(stack, child)
+			}
+		} else if l, ok := toSlice(val); ok {
+			stack = append(stack, l...)
+		}
+	}
+	return count
+}
+
+// Depth 返回最大嵌套深度，标量为0，{"a":1}和[]为1，{"a":{"b":1}}为2。使用显式栈遍历
+func (j *GoJson) Depth() int {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	type item struct {
+		val   interface{}
+		depth int
+	}
+	maxDepth := 0
+	stack := []item{{j.data, 0}}
+	for len(stack) > 0 {
+		it := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		var items []interface{}
+		if m, ok := toMap(it.val); ok {
+			for _, child := range m {
+				items = append(items, child)
+			}
+		} else if l, ok := toSlice(it.val); ok {
+			items = l
+		} else {
+			continue
+		}
+		if it.depth+1 > maxDepth {
+			maxDepth = it.depth + 1
+		}
+		for _, child := range items {
+			stack = append(stack, item{child, it.depth + 1})
+		}
+	}
+	return maxDepth
+}
+
+func walkVal(path string, val interface{}, fn func(path string, value interface{}) bool) bool {
+	if !fn(path, val) {
+		return false
+	}
+
+	join := func(seg string) string {
+		if path == "" {
+			return seg
+		}
+		return path + "." + seg
+	}
+	switch valV := val.(type) {
+	case Dict, map[string]interface{}, *OrderedDict:
+		m, _ := toMap(valV)
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if !walkVal(join(escapeKey(key, ".")), m[key], fn) {
+				return false
+			}
+		}
+	case List, []interface{}:
+		l, _ := toSlice(valV)
+		for i, item := range l {
+			if !walkVal(join(strconv.Itoa(i)), item, fn) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// walkEntry snapshotWalk收集的节点
+type walkEntry struct {
+	path  string
+	value interface{}
+}
+
+// snapshotWalk 在读锁内按walkVal的顺序收集节点，leavesOnly为true时只收集标量以及空的map和数组。
+// Walk、RangeDeep在锁外对结果调用回调
+func (j *GoJson) snapshotWalk(leavesOnly bool) []walkEntry {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	var entries []walkEntry
+	walkVal("", j.data, func(path string, value interface{}) bool {
+		if leavesOnly {
+			if m, ok := toMap(value); ok && len(m) > 0 {
+				return true
+			}
+			if l, ok := toSlice(value); ok && len(l) > 0 {
+				return true
+			}
+		}
+		entries = append(entries, walkEntry{path, value})
+		return true
+	})
+	return entries
+}
+
+// Walk 深度优先遍历整棵树，包括map、数组和标量节点，fn的path为GetPath格式的路径，根节点为""。
+// map按key的字典序遍历，fn返回false时遍历立刻结束
+func (j *GoJson) Walk(fn func(path string, value interface{}) bool) {
+	for _, entry := range j.snapshotWalk(false) {
+		if !fn(entry.path, entry.value) {
+			return
+		}
+	}
+}
+
+// walkNodes 与walkVal的顺序相同，但为每个值创建与父节点关联的节点，调用方已持有读锁
+func walkNodes(path string, node *GoJson, visit func(path string, node *GoJson)) {
+	visit(path, node)
+
+	if m, ok := toMap(node.data); ok {
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			walkNodes(joinPath(path, escapeKey(key, ".")), node.get(key), visit)
+		}
+	} else if l, ok := toSlice(node.data); ok {
+		for i := range l {
+			walkNodes(joinPath(path, strconv.Itoa(i)), node.index(i), visit)
+		}
+	}
+}
+
+// snapshotNodes 在读锁内通过walkNodes收集所有值和对应的节点，SelectAll在锁外对结果调用回调
+func (j *GoJson) snapshotNodes() ([]walkEntry, []*GoJson) {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	var entries []walkEntry
+	var nodes []*GoJson
+	walkNodes("", j, func(path string, node *GoJson) {
+		entries = append(entries, walkEntry{path, node.data})
+		nodes = append(nodes, node)
+	})
+	return entries, nodes
 }
 
-// Bytes 返回GoJson对象的bytes值
-func (j *GoJson) Bytes() []byte {
-	if j.data == nil {
-		return []byte("")
-	}
-	switch j.data.(type) {
-	case map[string]interface{}, []interface{}, Dict, List:
-		result, err := json.Marshal(j.data)
-		if err != nil {
-			log.Println("convert to bytes is error", err)
-			return []byte("")
+// SelectAll 深度优先遍历整棵树，返回pred返回true的所有节点(包括根节点自身)，path与Walk相同，如 "items.0.name"。
+// 返回的节点在遍历时逐层创建，与父节点关联，可以继续访问和修改，通过Path可以取得其路径
+func (j *GoJson) SelectAll(pred func(path string, val interface{}) bool) []*GoJson {
+	entries, nodes := j.snapshotNodes()
+	result := make([]*GoJson, 0)
+	for i, entry := range entries {
+		if pred(entry.path, entry.value) {
+			result = append(result, nodes[i])
 		}
-		return result
-	default:
-		return []byte(ToString(j.data))
 	}
+	return result
 }
 
-// Int 返回GoJson对象的源数据, 并尝试转换为int
-func (j *GoJson) Int() (int, error) {
-	v := j.data
-	if v == nil {
-		return 0, errors.New(fmt.Sprintf("%v is not int", j.data))
+// Path 返回节点相对于所在树的根节点的GetPath格式的路径，根节点为""，通过根节点的GetPath可以重新得到该节点
+func (j *GoJson) Path() string {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	var segments []string
+	for node := j; node.prev != nil; node = node.prev {
+		if _, ok := toSlice(node.prev.data); ok {
+			segments = append(segments, strconv.Itoa(node.prevIndex))
+		} else {
+			segments = append(segments, escapeKey(node.prevKey, "."))
+		}
 	}
-	return ToInt(v)
+	path := ""
+	for i := len(segments) - 1; i >= 0; i-- {
+		path = joinPath(path, segments[i])
+	}
+	return path
 }
 
-// Float64 返回GoJson对象的源数据, 并尝试转换为float64
-func (j *GoJson) Float64() (float64, error) {
-	v := j.data
-	if v == nil {
-		return 0, errors.New(fmt.Sprintf("%v is not float64", j.data))
+// RangeDeep 深度优先遍历所有叶子节点，即标量以及空的map和数组，f的path为GetPath格式的路径，与Flatten的key一致。
+// map按key的字典序遍历，f返回false时整个遍历立刻结束。需要同时访问map和数组节点时使用Walk
+func (j *GoJson) RangeDeep(f func(path string, val interface{}) bool) {
+	for _, entry := range j.snapshotWalk(true) {
+		if !f(entry.path, entry.value) {
+			return
+		}
 	}
-	return ToFloat64(v)
 }
 
-// Bool 返回GoJson对象的源数据, 并尝试转换为bool
-func (j *GoJson) Bool() (bool, error) {
-	return ToBool(j.data)
-}
+// RedactedValue Redact替换敏感值时使用的值
+const RedactedValue = "***"
 
-// Array 返回数组对象的源数据，如果源数据不是数组，则返回error
-func (j *GoJson) Array() ([]interface{}, error) {
-	if j.IsSlice() == false {
-		return nil, fmt.Errorf("%v is not array", j.data)
+// redactVal 原地将match为true的key对应的值替换为RedactedValue
+func redactVal(val interface{}, match func(key string) bool) {
+	if m, ok := toMap(val); ok {
+		for key, item := range m {
+			if match(key) {
+				m[key] = RedactedValue
+				continue
+			}
+			redactVal(item, match)
+		}
+		return
+	}
+	if l, ok := toSlice(val); ok {
+		for _, item := range l {
+			redactVal(item, match)
+		}
 	}
+}
 
-	switch v := j.data.(type) {
-	case List:
-		return v, nil
-	case []interface{}:
-		return v, nil
-	default:
-		return nil, fmt.Errorf("%v is not array", j.data)
+// Redact 返回一个副本，树中任意位置key为keys之一的值都被替换为"***"，key区分大小写，原对象保持不变。
+// 常用于打印日志前隐藏password、token等字段
+func (j *GoJson) Redact(keys ...string) *GoJson {
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		set[key] = true
 	}
+	return j.RedactFunc(func(key string) bool {
+		return set[key]
+	})
 }
 
-// IsString 如果json值为string, 则返回true, 否则false
-func (j *GoJson) IsString() bool {
-	return fmt.Sprintf("%T", j.data) == "string"
+// RedactIgnoreCase 与Redact相同，但key不区分大小写
+func (j *GoJson) RedactIgnoreCase(keys ...string) *GoJson {
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		set[strings.ToLower(key)] = true
+	}
+	return j.RedactFunc(func(key string) bool {
+		return set[strings.ToLower(key)]
+	})
 }
 
-// Type 返回json值的类型, see: fmt.Sprintf("%T", foo)
-func (j *GoJson) Type() string {
-	return fmt.Sprintf("%T", j.data)
+// RedactFunc 返回一个副本，树中任意位置match返回true的key对应的值都被替换为"***"，原对象保持不变
+func (j *GoJson) RedactFunc(match func(key string) bool) *GoJson {
+	clone := j.Clone()
+	redactVal(clone.data, match)
+	return clone
 }
 
-// RangeMap 遍历kv结构， 传入的函数用于处理遍历。如果这个函数返回false，遍历将立刻结束
-func (j *GoJson) RangeMap(f func(key string, val interface{}) bool) error {
-	if j.IsMap() == false {
-		return fmt.Errorf("%v is not map", j.data)
+// isNumberString 判断s是否为合法的json数字，如 42、-1.5e3。前导0、+号、空白等不是json数字的写法返回false
+func isNumberString(s string) bool {
+	if s == "" || s[0] != '-' && (s[0] < '0' || s[0] > '9') || s[len(s)-1] < '0' || s[len(s)-1] > '9' {
+		return false
 	}
-	switch v := j.data.(type) {
-	case Dict:
-		for key, val := range v {
-			ret := f(key, val)
-			if !ret {
-				break
-			}
+	return sysjson.Valid([]byte(s))
+}
+
+// coerceNumbers 将val中是合法数字的字符串原地转换为json.Number并返回转换后的val。
+// coerce为false时只处理keys中的key对应的值，数组继承所在位置的coerce
+func coerceNumbers(val interface{}, coerce bool, keys map[string]bool) interface{} {
+	if m, ok := toMap(val); ok {
+		for key, item := range m {
+			m[key] = coerceNumbers(item, coerce || keys[key], keys)
 		}
-	case map[string]interface{}:
-		for key, val := range v {
-			ret := f(key, val)
-			if !ret {
-				break
-			}
+		return val
+	}
+	if l, ok := toSlice(val); ok {
+		for i, item := range l {
+			l[i] = coerceNumbers(item, coerce, keys)
 		}
+		return val
 	}
-	return nil
+	if s, ok := val.(string); ok && coerce && isNumberString(s) {
+		return sysjson.Number(s)
+	}
+	return val
 }
 
-// RangeSlice 遍历数组结构， 传入的函数用于处理遍历。如果这个函数返回false，遍历将立刻结束
-func (j *GoJson) RangeSlice(f func(index int, val interface{}) bool) error {
-	if j.IsSlice() == false {
-		return fmt.Errorf("%v is not Slice", j.data)
+// CoerceNumbers 将树中是合法json数字的字符串(如 "42"、"-1.5")原地转换为json.Number并返回自身。
+// keys为空时转换所有字符串，否则只转换任意位置key为keys之一的值(包括其下的数组和嵌套结构)，避免误转字符串类型的ID。
+// "007"、"+1"、" 1"等不是json数字写法的字符串保持不变
+func (j *GoJson) CoerceNumbers(keys ...string) *GoJson {
+	r := j.root()
+	r.Lock()
+	defer r.Unlock()
+	if err := r.checkFrozen("coerce"); err != nil {
+		log.Println(err)
+		return j
 	}
-	switch v := j.data.(type) {
-	case List:
-		for key, val := range v {
-			ret := f(key, val)
-			if !ret {
-				break
-			}
-		}
-	case []interface{}:
-		for key, val := range v {
-			ret := f(key, val)
-			if !ret {
-				break
-			}
-		}
+
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		set[key] = true
 	}
-	return nil
+	if _, ok := j.data.(string); ok {
+		j.data = coerceNumbers(j.data, len(keys) == 0, set)
+		maintainParent(j)
+		return j
+	}
+	coerceNumbers(j.data, len(keys) == 0, set)
+	return j
 }
 
-func handlerVal(val interface{}, cutLongStr bool) interface{} {
+func handlerVal(val interface{}, maxLen int) interface{} {
 	switch valV := val.(type) {
 	case Dict:
-		return handlerMap(valV, cutLongStr)
+		return handlerMap(valV, maxLen)
 	case map[string]interface{}:
-		return handlerMap(valV, cutLongStr)
+		return handlerMap(valV, maxLen)
+	case *OrderedDict:
+		ret := NewOrderedDict()
+		for _, key := range valV.Keys() {
+			ret.Set(key, handlerVal(valV.values[key], maxLen))
+		}
+		return ret
 	case List:
-		return handlerSlice(valV, cutLongStr)
+		return handlerSlice(valV, maxLen)
 	case []interface{}:
-		return handlerSlice(valV, cutLongStr)
+		return handlerSlice(valV, maxLen)
 	case string:
-		return handlerString(valV, cutLongStr)
+		return handlerString(valV, maxLen)
 	default:
 		return valV
 	}
 }
 
-func handlerMap(js interface{}, cutLongStr bool) Dict {
+func handlerMap(js interface{}, maxLen int) Dict {
 	ret := NewDict()
 	switch v := js.(type) {
 	case Dict:
 		for key, val := range v {
-			ret[key] = handlerVal(val, cutLongStr)
+			ret[key] = handlerVal(val, maxLen)
 		}
 	case map[string]interface{}:
 		for key, val := range v {
-			ret[key] = handlerVal(val, cutLongStr)
+			ret[key] = handlerVal(val, maxLen)
 		}
 	}
 	return ret
 }
 
-func handlerSlice(js interface{}, cutLongStr bool) List {
+func handlerSlice(js interface{}, maxLen int) List {
 	ret := NewList()
 	switch v := js.(type) {
 	case List:
 		for _, val := range v {
-			ret = append(ret, handlerVal(val, cutLongStr))
+			ret = append(ret, handlerVal(val, maxLen))
 		}
 	case []interface{}:
 		for _, val := range v {
-			ret = append(ret, handlerVal(val, cutLongStr))
+			ret = append(ret, handlerVal(val, maxLen))
 		}
 	}
 	return ret
 }
 
-func handlerString(js string, cutLongStr bool) string {
-	if cutLongStr && len(js) > 120 {
-		return js[:120] + "......"
+// handlerString 字符串超过maxLen个字符时截断并加上ShortNiceJsonSuffix，按字符截断，不会破坏UTF-8编码
+func handlerString(js string, maxLen int) string {
+	if maxLen <= 0 || len(js) <= maxLen || utf8.RuneCountInString(js) <= maxLen {
+		return js
+	}
+	count := 0
+	for i := range js {
+		if count == maxLen {
+			return js[:i] + ShortNiceJsonSuffix
+		}
+		count++
 	}
 	return js
 }
 
-// ShortNiceJson 性能差，返回处理过的json，这个json中所有的字符串都被截断成不超过20个字符的数据
+// ShortNiceJsonSuffix ShortNiceJson截断字符串后追加的后缀
+var ShortNiceJsonSuffix = "......"
+
+// ShortNiceJson 性能差，返回处理过的json，这个json中所有的字符串都被截断成不超过120个字符的数据
 func (j *GoJson) ShortNiceJson() *GoJson {
-	cutLongStr := true
-	if j.IsSlice() {
-		return NewJson(handlerSlice(j.data, cutLongStr))
+	return j.ShortNiceJsonN(120)
+}
+
+// ShortNiceJsonN 性能差，返回处理过的json，这个json中所有的字符串都被截断成不超过maxLen个字符的数据，
+// 截断后追加ShortNiceJsonSuffix。maxLen小于等于0时不截断
+func (j *GoJson) ShortNiceJsonN(maxLen int) *GoJson {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	if _, ok := toSlice(j.data); ok {
+		return NewJson(handlerSlice(j.data, maxLen))
+	}
+	if _, ok := j.data.(*OrderedDict); ok {
+		return NewJson(handlerVal(j.data, maxLen))
 	}
-	if j.IsMap() {
-		return NewJson(handlerMap(j.data, cutLongStr))
+	if _, ok := toMap(j.data); ok {
+		return NewJson(handlerMap(j.data, maxLen))
 	}
 	return NewJson(j.data)
 }
 
-// Clone 把这个json对象clone一份，深复制，性能差
+// maxStringLen 返回val中最长的字符串的字符数
+func maxStringLen(val interface{}) int {
+	max := 0
+	walkVal("", val, func(path string, value interface{}) bool {
+		if str, ok := value.(string); ok {
+			if n := utf8.RuneCountInString(str); n > max {
+				max = n
+			}
+		}
+		return true
+	})
+	return max
+}
+
+// truncateString 将超过maxLen个字符的字符串截断为maxLen个字符并追加ShortNiceJsonSuffix，
+// 追加后缀后不会比原字符串短时保持不变
+func truncateString(s string, maxLen int) string {
+	suffixLen := utf8.RuneCountInString(ShortNiceJsonSuffix)
+	if len(s) <= maxLen+suffixLen || utf8.RuneCountInString(s) <= maxLen+suffixLen {
+		return s
+	}
+	count := 0
+	for i := range s {
+		if count == maxLen {
+			return s[:i] + ShortNiceJsonSuffix
+		}
+		count++
+	}
+	return s
+}
+
+// truncateVal 与deepCopy相同，但其中的字符串经truncateString截断，map和数组的原有类型保持不变
+func truncateVal(val interface{}, maxLen int) interface{} {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = truncateVal(item, maxLen)
+		}
+		return result
+	case Dict:
+		result := make(Dict, len(v))
+		for key, item := range v {
+			result[key] = truncateVal(item, maxLen)
+		}
+		return result
+	case *OrderedDict:
+		result := NewOrderedDict()
+		for _, key := range v.Keys() {
+			result.Set(key, truncateVal(v.values[key], maxLen))
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = truncateVal(item, maxLen)
+		}
+		return result
+	case List:
+		result := make(List, len(v))
+		for i, item := range v {
+			result[i] = truncateVal(item, maxLen)
+		}
+		return result
+	case string:
+		return truncateString(v, maxLen)
+	default:
+		return v
+	}
+}
+
+// TruncateTo 返回一个副本，通过截断字符串使其json编码(见Bytes)尽量不超过maxBytes字节：优先截断最长的字符串，
+// 截断的字符串末尾追加ShortNiceJsonSuffix，key、数字等其他值以及map和数组的类型保持不变。
+// 只截断字符串无法满足时(如所有字符串都截断到1个字符仍然超出)，返回截断程度最大的结果，其编码会超过maxBytes，
+// 需要严格限制大小时调用方应检查结果的长度。原对象保持不变
+func (j *GoJson) TruncateTo(maxBytes int) *GoJson {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	size := func(val interface{}) int {
+		b, err := marshal(val)
+		if err != nil {
+			return 0
+		}
+		return len(b)
+	}
+	if size(j.data) <= maxBytes {
+		return NewJsonFromData(deepCopy(j.data))
+	}
+
+	// 二分查找最大的字符数上限，使截断后的结果不超过maxBytes
+	best := truncateVal(j.data, 1)
+	low, high := 1, maxStringLen(j.data)
+	for low <= high {
+		mid := (low + high) / 2
+		truncated := truncateVal(j.data, mid)
+		if size(truncated) <= maxBytes {
+			best = truncated
+			low = mid + 1
+		} else {
+			high = mid - 1
+		}
+	}
+	return NewJsonFromData(best)
+}
+
+// Clone 把这个json对象clone一份，深复制，map和数组的原有类型保持不变，修改clone不会影响原对象
 func (j *GoJson) Clone() *GoJson {
-	cutLongStr := false
-	if j.IsSlice() {
-		return NewJson(handlerSlice(j.data, cutLongStr))
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	return NewJsonFromData(deepCopy(j.data))
+}
+
+// Freeze 将当前对象所在的整棵树冻结为只读并返回自身。冻结后Set、Append、Insert、Remove、Merge等修改方法
+// 什么都不会发生并打印日志，返回error的修改方法(SetPath、InsertE、ApplyPatch等)返回error，冻结无法解除。
+// 冻结只限制GoJson的方法，通过Value、RangeMap等取得的map和数组本身仍可被修改，需要修改时先Clone
+func (j *GoJson) Freeze() *GoJson {
+	r := j.root()
+	r.Lock()
+	defer r.Unlock()
+
+	r.frozen = true
+	return j
+}
+
+// Frozen 判断当前对象所在的树是否已通过Freeze冻结
+func (j *GoJson) Frozen() bool {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	return r.frozen
+}
+
+// checkFrozen 在根节点上调用，已冻结时返回error，需在持有锁时调用
+func (j *GoJson) checkFrozen(op string) error {
+	if j.frozen {
+		return fmt.Errorf("json is frozen cannot %s", op)
 	}
-	if j.IsMap() {
-		return NewJson(handlerMap(j.data, cutLongStr))
+	return nil
+}
+
+// deepCopy 深复制map和数组，保留Dict、List等原有类型，标量直接复制
+func deepCopy(val interface{}) interface{} {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = deepCopy(item)
+		}
+		return result
+	case Dict:
+		result := make(Dict, len(v))
+		for key, item := range v {
+			result[key] = deepCopy(item)
+		}
+		return result
+	case *OrderedDict:
+		result := NewOrderedDict()
+		for _, key := range v.Keys() {
+			result.Set(key, deepCopy(v.values[key]))
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = deepCopy(item)
+		}
+		return result
+	case List:
+		result := make(List, len(v))
+		for i, item := range v {
+			result[i] = deepCopy(item)
+		}
+		return result
+	default:
+		return v
 	}
-	return NewJson(j.data)
 }
 
 func NewList() List {
@@ -688,45 +4209,82 @@ func ToString(obj interface{}) string {
 	}
 }
 
+// formatFloat 与json编码的格式一致，整数值的浮点数不会输出为科学计数法
+func formatFloat(f float64, bits int) string {
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	return strconv.FormatFloat(f, format, -1, bits)
+}
+
 func ToJsonString(obj interface{}) string {
-	bytes, _ := json.Marshal(obj)
+	bytes, _ := marshal(obj)
 	return string(bytes)
 }
 
+// maxInt、minInt 当前平台int的取值范围，32位机上为int32的范围
+const (
+	maxInt = int(^uint(0) >> 1)
+	minInt = -maxInt - 1
+)
+
+// ToInt 转换为int，超出当前平台int范围时返回error
 func ToInt(intObj interface{}) (int, error) {
-	// 假定int == int64，运行在64位机
+	v, err := ToInt64(intObj)
+	if err != nil {
+		return 0, err
+	}
+	if v > int64(maxInt) || v < int64(minInt) {
+		return 0, fmt.Errorf("ToInt, error, overflowd %v", intObj)
+	}
+	return int(v), nil
+}
+
+// ToInt64 转换为int64，与平台无关
+func ToInt64(intObj interface{}) (int64, error) {
 	switch v := intObj.(type) {
 	case sysjson.Number:
 		vint64, err := v.Int64()
-		return int(vint64), err
+		if err != nil {
+			return 0, fmt.Errorf("%v cannot convert to int", intObj)
+		}
+		return vint64, nil
 	case int:
-		return v, nil
+		return int64(v), nil
 	case int8:
-		return int(v), nil
+		return int64(v), nil
 	case int16:
-		return int(v), nil
+		return int64(v), nil
 	case int32:
-		return int(v), nil
+		return int64(v), nil
 	case int64:
-		return int(v), nil
+		return v, nil
 	case uint:
-		return int(v), nil
+		if uint64(v) > math.MaxInt64 {
+			return 0, fmt.Errorf("ToInt, error, overflowd %v", v)
+		}
+		return int64(v), nil
 	case uint8:
-		return int(v), nil
+		return int64(v), nil
 	case uint16:
-		return int(v), nil
+		return int64(v), nil
 	case uint32:
-		return int(v), nil
+		return int64(v), nil
 	case uint64:
 		if v > math.MaxInt64 {
-			info := fmt.Sprintf("ToInt, error, overflowd %v", v)
-			return 0, errors.New(info)
+			return 0, fmt.Errorf("ToInt, error, overflowd %v", v)
 		}
-		return int(v), nil
+		return int64(v), nil
 	case float32:
-		return int(v), nil
+		return ToInt64(float64(v))
 	case float64:
-		return int(v), nil
+		if v >= math.MaxInt64 || v < math.MinInt64 || math.IsNaN(v) {
+			return 0, fmt.Errorf("ToInt, error, overflowd %v", v)
+		}
+		return int64(v), nil
 	case string:
 		strv := v
 		if strings.Contains(v, ".") {
@@ -735,19 +4293,89 @@ func ToInt(intObj interface{}) (int, error) {
 		if strv == "" {
 			return 0, nil
 		}
-		if intv, err := strconv.Atoi(strv); err == nil {
+		if intv, err := strconv.ParseInt(strv, 10, 64); err == nil {
 			return intv, nil
 		}
 	}
 	return 0, fmt.Errorf("%v cannot convert to int", intObj)
 }
 
+// ToUint64 转换为uint64，可表示超出int64范围的无符号整数，负数返回error
+func ToUint64(uintObj interface{}) (uint64, error) {
+	switch v := uintObj.(type) {
+	case sysjson.Number:
+		vuint64, err := strconv.ParseUint(string(v), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%v cannot convert to uint64", uintObj)
+		}
+		return vuint64, nil
+	case int, int8, int16, int32, int64:
+		vint64, _ := ToInt64(v)
+		if vint64 < 0 {
+			return 0, fmt.Errorf("%v is negative, cannot convert to uint64", v)
+		}
+		return uint64(vint64), nil
+	case uint:
+		return uint64(v), nil
+	case uint8:
+		return uint64(v), nil
+	case uint16:
+		return uint64(v), nil
+	case uint32:
+		return uint64(v), nil
+	case uint64:
+		return v, nil
+	case float32:
+		return ToUint64(float64(v))
+	case float64:
+		if v < 0 || v >= math.MaxUint64 || math.IsNaN(v) {
+			return 0, fmt.Errorf("ToUint64, error, overflowd %v", v)
+		}
+		return uint64(v), nil
+	case string:
+		strv := v
+		if strings.Contains(v, ".") {
+			strv = strings.Split(v, ".")[0]
+		}
+		if strv == "" {
+			return 0, nil
+		}
+		if uintv, err := strconv.ParseUint(strv, 10, 64); err == nil {
+			return uintv, nil
+		}
+	}
+	return 0, fmt.Errorf("%v cannot convert to uint64", uintObj)
+}
+
+// ToNumber 转换为json.Number，只接受数字类型，NaN和Inf返回error
+func ToNumber(item interface{}) (sysjson.Number, error) {
+	switch v := item.(type) {
+	case sysjson.Number:
+		return v, nil
+	case int, int8, int16, int32, int64:
+		n, _ := ToInt64(v)
+		return sysjson.Number(strconv.FormatInt(n, 10)), nil
+	case uint, uint8, uint16, uint32, uint64:
+		n, _ := ToUint64(v)
+		return sysjson.Number(strconv.FormatUint(n, 10)), nil
+	case float32:
+		if !math.IsNaN(float64(v)) && !math.IsInf(float64(v), 0) {
+			return sysjson.Number(formatFloat(float64(v), 32)), nil
+		}
+	case float64:
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			return sysjson.Number(formatFloat(v, 64)), nil
+		}
+	}
+	return "", fmt.Errorf("%v cannot convert to number", item)
+}
+
 func ToFloat64(item interface{}) (float64, error) {
 	switch v := item.(type) {
 	case sysjson.Number:
 		return v.Float64()
-	case int, int8, int16, int64, uint, uint8, uint16, uint32, uint64:
-		intVal, err := ToInt(item)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		intVal, err := ToInt64(item)
 		return float64(intVal), err
 	case float64:
 		return v, nil
@@ -761,16 +4389,106 @@ func ToFloat64(item interface{}) (float64, error) {
 	return 0, fmt.Errorf("%v cannot convert to float", item)
 }
 
+// ToBool 转换为bool：nil为false；数字(含json.Number)非0为true；
+// 字符串不区分大小写并忽略首尾空白，"1"、"t"、"true"、"y"、"yes"、"on" 为true，
+// "0"、"f"、"false"、"n"、"no"、"off" 为false；其他值返回error
 func ToBool(item interface{}) (bool, error) {
-
 	switch v := item.(type) {
+	case nil:
+		return false, nil
 	case bool:
 		return v, nil
-	default:
-		boolValue, err := strconv.ParseBool(ToString(item))
+	case sysjson.Number, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		floatValue, err := ToFloat64(v)
 		if err != nil {
 			return false, fmt.Errorf("%v cannot convert to bool", item)
 		}
-		return boolValue, nil
+		return floatValue != 0, nil
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "1", "t", "true", "y", "yes", "on":
+			return true, nil
+		case "0", "f", "false", "n", "no", "off":
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("%v cannot convert to bool", item)
+}
+
+// ToBytes 转换为[]byte：[]byte原样返回；字符串按标准base64解码，末尾的=可以省略；null返回nil。其他值返回error
+func ToBytes(item interface{}) ([]byte, error) {
+	return toBytes(item, base64.StdEncoding, base64.RawStdEncoding)
+}
+
+func toBytes(item interface{}, encodings ...*base64.Encoding) ([]byte, error) {
+	switch v := item.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return v, nil
+	case string:
+		var err error
+		for _, encoding := range encodings {
+			var b []byte
+			if b, err = encoding.DecodeString(v); err == nil {
+				return b, nil
+			}
+		}
+		return nil, fmt.Errorf("%v is not base64: %v", item, err)
+	}
+	return nil, fmt.Errorf("%v cannot convert to bytes", item)
+}
+
+// unixMillisThreshold 绝对值不小于该值的数字按毫秒时间戳处理，否则按秒处理
+const unixMillisThreshold = 1e12
+
+func unixTime(n int64) time.Time {
+	if n >= unixMillisThreshold || n <= -unixMillisThreshold {
+		return time.Unix(n/1000, n%1000*int64(time.Millisecond))
+	}
+	return time.Unix(n, 0)
+}
+
+func unixTimeFloat(f float64) time.Time {
+	if math.Abs(f) >= unixMillisThreshold {
+		f /= 1000
+	}
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(frac*float64(time.Second)))
+}
+
+// ToTime 转换为time.Time：字符串先按RFC3339解析，再依次尝试layouts；
+// 数字(含json.Number)按Unix秒解析，绝对值不小于1e12时按Unix毫秒解析。无法解析时返回零值和error
+func ToTime(item interface{}, layouts ...string) (time.Time, error) {
+	switch v := item.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t, nil
+		}
+		for _, layout := range layouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, nil
+			}
+		}
+	case sysjson.Number:
+		if n, err := v.Int64(); err == nil {
+			return unixTime(n), nil
+		}
+		if f, err := v.Float64(); err == nil {
+			return unixTimeFloat(f), nil
+		}
+	case float32:
+		return unixTimeFloat(float64(v)), nil
+	case float64:
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			return unixTimeFloat(v), nil
+		}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		if n, err := ToInt64(v); err == nil {
+			return unixTime(n), nil
+		}
 	}
+	return time.Time{}, fmt.Errorf("%v cannot convert to time", item)
 }
@@ -0,0 +1,946 @@
+package gojson
+
+import (
+	sysjson "encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// compact 返回去掉末尾换行的String结果，便于比较
+func compact(j *GoJson) string {
+	return strings.TrimSpace(j.String())
+}
+
+func TestRemoveSliceIndex(t *testing.T) {
+	cases := []struct {
+		name  string
+		index int
+		want  string
+	}{
+		{"middle", 1, `{"arr":[1,3,4]}`},
+		{"first", 0, `{"arr":[2,3,4]}`},
+		{"last", 3, `{"arr":[1,2,3]}`},
+		{"negative last", -1, `{"arr":[1,2,3]}`},
+		{"negative first", -4, `{"arr":[2,3,4]}`},
+		{"out of range", 4, `{"arr":[1,2,3,4]}`},
+		{"negative out of range", -5, `{"arr":[1,2,3,4]}`},
+	}
+	for _, c := range cases {
+		j := NewJsonFromString(`{"arr":[1,2,3,4]}`)
+		j.Get("arr").Remove(c.index)
+		if got := compact(j); got != c.want {
+			t.Errorf("%s: Remove(%d) = %s, want %s", c.name, c.index, got, c.want)
+		}
+	}
+
+	list := NewJsonFromData(List{1, 2, 3})
+	list.Remove(1)
+	if got := compact(list); got != `[1,3]` {
+		t.Errorf("Remove on List = %s", got)
+	}
+	if _, ok := list.Value().(List); !ok {
+		t.Errorf("Remove on List changed type to %T", list.Value())
+	}
+}
+
+func TestEquals(t *testing.T) {
+	cases := []struct {
+		a, b interface{}
+		want bool
+	}{
+		{`{"a":1,"b":[1,2]}`, `{"b":[1,2],"a":1}`, true},
+		{`[1,2]`, `[2,1]`, false},
+		{`{"n":1}`, map[string]interface{}{"n": 1}, true},
+		{`{"n":1}`, map[string]interface{}{"n": 1.0}, true},
+		{`{"n":1.50}`, map[string]interface{}{"n": 1.5}, true},
+		{`{"n":1e2}`, map[string]interface{}{"n": int64(100)}, true},
+		{`{"n":1}`, map[string]interface{}{"n": "1"}, false},
+		{`{"n":1}`, map[string]interface{}{"n": 2}, false},
+		{`{"n":1e9999999}`, `{"n":1e9999999}`, true},
+		{`{"n":1e9999999}`, `{"n":-1e9999999}`, false},
+		{`{"n":1e-9999999}`, map[string]interface{}{"n": 0}, true},
+		{`{"a":[1]}`, `{"a":1}`, false},
+		{`{"a":{}}`, `{"a":[]}`, false},
+		{`null`, nil, true},
+		{`{"a":1}`, nil, false},
+	}
+	for _, c := range cases {
+		a := NewJson(c.a)
+		var b *GoJson
+		if s, ok := c.b.(string); ok {
+			b = NewJson(s)
+		} else {
+			b = NewJsonFromData(c.b)
+		}
+		if got := a.Equals(b); got != c.want {
+			t.Errorf("%v Equals %v = %v, want %v", c.a, c.b, got, c.want)
+		}
+	}
+
+	var nilJson *GoJson
+	if NewJsonFromString(`{}`).Equals(nilJson) {
+		t.Error("non-null should not equal nil")
+	}
+	if !NewJsonFromData(nil).Equals(nilJson) {
+		t.Error("null should equal nil")
+	}
+}
+
+func TestHugeExponent(t *testing.T) {
+	j := NewJsonFromString(`{"n":1e9999999,"m":-1e9999999,"small":1e-9999999}`)
+
+	canonical, err := j.Canonical()
+	if err != nil || string(canonical) != `{"m":-1e9999999,"n":1e9999999,"small":1e-9999999}` {
+		t.Errorf("Canonical = %s, %v", canonical, err)
+	}
+	if got := compareValues(j.Get("m").Value(), j.Get("n").Value()); got >= 0 {
+		t.Errorf("compareValues(-1e9999999, 1e9999999) = %d", got)
+	}
+
+	schema := NewJsonFromString(`{"properties":{"n":{"type":"number","maximum":100},"m":{"minimum":0}}}`)
+	if errs := j.ValidateSchema(schema); len(errs) != 2 {
+		t.Errorf("ValidateSchema = %v, want 2 errors", errs)
+	}
+}
+
+func TestInsertBounds(t *testing.T) {
+	cases := []struct {
+		index int
+		want  string
+	}{
+		{0, `{"arr":["x",1,2,3]}`},
+		{1, `{"arr":[1,"x",2,3]}`},
+		{3, `{"arr":[1,2,3,"x"]}`},
+		{10, `{"arr":[1,2,3,"x"]}`},
+		{-1, `{"arr":[1,2,"x",3]}`},
+		{-10, `{"arr":["x",1,2,3]}`},
+	}
+	for _, c := range cases {
+		j := NewJsonFromString(`{"arr":[1,2,3]}`)
+		j.Get("arr").Insert(c.index, "x")
+		if got := compact(j); got != c.want {
+			t.Errorf("Insert(%d) = %s, want %s", c.index, got, c.want)
+		}
+	}
+
+	if err := NewJsonFromString(`{"a":1}`).InsertE(0, "x"); err == nil {
+		t.Error("InsertE on map should return error")
+	}
+	if err := NewJsonFromString(`[]`).InsertE(5, "x"); err != nil {
+		t.Errorf("InsertE on array returned %v", err)
+	}
+}
+
+func TestExistsNullVsMissing(t *testing.T) {
+	j := NewJsonFromString(`{"present_null":null,"present":1,"arr":[null]}`)
+	cases := []struct {
+		name   string
+		node   *GoJson
+		exists bool
+		isNil  bool
+	}{
+		{"present null", j.Get("present_null"), true, true},
+		{"present", j.Get("present"), true, false},
+		{"absent", j.Get("absent"), false, true},
+		{"null element", j.Get("arr").Index(0), true, true},
+		{"absent element", j.Get("arr").Index(1), false, true},
+		{"under absent", j.Get("absent").Get("x"), false, true},
+	}
+	for _, c := range cases {
+		if got := c.node.Exists(); got != c.exists {
+			t.Errorf("%s: Exists() = %v, want %v", c.name, got, c.exists)
+		}
+		if got := c.node.IsNil(); got != c.isNil {
+			t.Errorf("%s: IsNil() = %v, want %v", c.name, got, c.isNil)
+		}
+	}
+}
+
+func TestMarshalRoundTripInStruct(t *testing.T) {
+	type envelope struct {
+		Name string  `json:"name"`
+		Data *GoJson `json:"data"`
+	}
+	in := envelope{Name: "x", Data: NewJsonFromString(`{"a":[1,"<b>",null],"n":1.50}`)}
+	b, err := sysjson.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"name":"x","data":{"a":[1,"\u003cb\u003e",null],"n":1.50}}`; string(b) != want {
+		t.Errorf("Marshal = %s, want %s", b, want)
+	}
+
+	var out envelope
+	if err := sysjson.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Name != "x" || !out.Data.Equals(in.Data) {
+		t.Errorf("round trip = %+v", out)
+	}
+	if v, _ := out.Data.Get("n").Value().(sysjson.Number); v != "1.50" {
+		t.Errorf("number changed to %s", v)
+	}
+
+	b, err = json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var viaIter envelope
+	if err := json.Unmarshal(b, &viaIter); err != nil || !viaIter.Data.Equals(in.Data) {
+		t.Errorf("jsoniter round trip failed: %v", err)
+	}
+}
+
+func TestToIntOverflow(t *testing.T) {
+	// 比当前平台int的范围大1的数，32位机上走int范围检查，64位机上走int64解析失败
+	aboveInt := sysjson.Number(strconv.FormatUint(uint64(maxInt)+1, 10))
+	belowInt := sysjson.Number("-" + strconv.FormatUint(uint64(maxInt)+2, 10))
+	cases := []struct {
+		in     interface{}
+		want   int
+		wantOk bool
+	}{
+		{sysjson.Number("42"), 42, true},
+		{sysjson.Number(strconv.Itoa(maxInt)), maxInt, true},
+		{sysjson.Number(strconv.Itoa(minInt)), minInt, true},
+		{aboveInt, 0, false},
+		{belowInt, 0, false},
+		{sysjson.Number("9223372036854775808"), 0, false},
+		{uint64(math.MaxUint64), 0, false},
+		{1e300, 0, false},
+		{math.NaN(), 0, false},
+	}
+	for _, c := range cases {
+		got, err := ToInt(c.in)
+		if (err == nil) != c.wantOk || got != c.want {
+			t.Errorf("ToInt(%v) = %d, %v", c.in, got, err)
+		}
+	}
+
+	if v, err := ToInt64(sysjson.Number("4294967296")); err != nil || v != 4294967296 {
+		t.Errorf("ToInt64 = %d, %v", v, err)
+	}
+	if _, err := ToInt64(sysjson.Number("9223372036854775808")); err == nil {
+		t.Error("ToInt64 should overflow")
+	}
+	if v, err := NewJsonFromString(`{"n":4294967296}`).Get("n").Int64(); err != nil || v != 4294967296 {
+		t.Errorf("Int64 = %d, %v", v, err)
+	}
+}
+
+func TestCloneIsDeep(t *testing.T) {
+	long := strings.Repeat("x", 500)
+	j := NewJsonFromString(`{"a":{"l":[1,{"b":2}]},"n":1.50,"s":"` + long + `"}`)
+	j.Set("list", List{1, 2})
+	j.Set("dict", Dict{"k": "v"})
+	clone := j.Clone()
+
+	clone.GetPath("a.l").Append(3)
+	clone.GetPath("a.l.1").Set("b", 3)
+	clone.Get("list").Set(0, "changed")
+	clone.Get("dict").Set("k", "changed")
+	if got := compact(j.Get("a")); got != `{"l":[1,{"b":2}]}` {
+		t.Errorf("original changed: %s", got)
+	}
+	if got := compact(j.Get("list")); got != `[1,2]` {
+		t.Errorf("original List changed: %s", got)
+	}
+	if got := compact(j.Get("dict")); got != `{"k":"v"}` {
+		t.Errorf("original Dict changed: %s", got)
+	}
+	if _, ok := clone.Get("list").Value().(List); !ok {
+		t.Errorf("Clone changed List to %T", clone.Get("list").Value())
+	}
+	if got := clone.GetString("s"); got != long {
+		t.Errorf("Clone truncated string to %d bytes", len(got))
+	}
+	if n, _ := clone.Get("n").Value().(sysjson.Number); n != "1.50" {
+		t.Errorf("Clone changed number to %s", n)
+	}
+}
+
+func TestSerializedSize(t *testing.T) {
+	var nilOrdered *OrderedDict
+	values := []interface{}{
+		`{"a":[1,"x",null,true],"b":{"c":1.50},"s":"<\u00e9>"}`,
+		map[string]interface{}{"m": map[string]interface{}(nil), "d": Dict(nil), "o": nilOrdered},
+		[]interface{}{[]interface{}(nil), List(nil), NewOrderedDict()},
+		map[string]interface{}(nil),
+	}
+	for _, v := range values {
+		var j *GoJson
+		if s, ok := v.(string); ok {
+			j = NewJsonFromString(s)
+		} else {
+			j = NewJsonFromData(v)
+		}
+		b, err := j.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := j.SerializedSize(); got != len(b) {
+			t.Errorf("SerializedSize = %d, want %d for %s", got, len(b), b)
+		}
+	}
+}
+
+func TestTruncateTo(t *testing.T) {
+	long := strings.Repeat("y", 100)
+	j := NewJsonFromString(`{"short":"abcdefgh","long":"` + long + `","n":[1,2]}`)
+	truncated := j.TruncateTo(60)
+	if got := len(truncated.Bytes()); got > 60 {
+		t.Errorf("TruncateTo(60) is %d bytes: %s", got, compact(truncated))
+	}
+	// 追加后缀后不会更短的字符串保持不变
+	if got := truncated.GetString("short"); got != "abcdefgh" {
+		t.Errorf("short = %q", got)
+	}
+	if got := truncated.GetString("long"); !strings.HasSuffix(got, ShortNiceJsonSuffix) {
+		t.Errorf("long = %q", got)
+	}
+	if got := j.GetString("long"); got != long {
+		t.Errorf("original changed to %q", got)
+	}
+
+	j = NewJsonFromData(map[string]interface{}{"l": []interface{}{long}, "list": List{long}, "dict": Dict{"k": long}})
+	truncated = j.TruncateTo(80)
+	if _, ok := truncated.Value().(map[string]interface{}); !ok {
+		t.Errorf("root changed to %T", truncated.Value())
+	}
+	if _, ok := truncated.Get("l").Value().([]interface{}); !ok {
+		t.Errorf("l changed to %T", truncated.Get("l").Value())
+	}
+	if _, ok := truncated.Get("list").Value().(List); !ok {
+		t.Errorf("list changed to %T", truncated.Get("list").Value())
+	}
+	if _, ok := truncated.Get("dict").Value().(Dict); !ok {
+		t.Errorf("dict changed to %T", truncated.Get("dict").Value())
+	}
+
+	// 只截断字符串无法满足时返回截断程度最大的结果
+	j = NewJsonFromString(`{"n":[1,2,3,4,5,6,7,8,9],"s":"` + long + `"}`)
+	if got := j.TruncateTo(10).GetString("s"); got != "y"+ShortNiceJsonSuffix {
+		t.Errorf("s = %q", got)
+	}
+}
+
+func TestNegativeIndex(t *testing.T) {
+	cases := []struct {
+		index    int
+		set      string
+		indexSet string
+	}{
+		{-1, `[1,2,"x"]`, `[[1],[2],[3,"x"]]`},
+		{-3, `["x",2,3]`, `[[1,"x"],[2],[3]]`},
+		{-4, `[1,2,3]`, `[[1],[2],[3]]`},
+	}
+	for _, c := range cases {
+		j := NewJsonFromString(`[1,2,3]`)
+		j.Set(c.index, "x")
+		if got := compact(j); got != c.set {
+			t.Errorf("Set(%d): got %s, want %s", c.index, got, c.set)
+		}
+
+		// 通过Index得到的节点写入后会写回，超出开头的负数下标不与父节点关联
+		j = NewJsonFromString(`[[1],[2],[3]]`)
+		j.Index(c.index).Set(1, "x")
+		if got := compact(j); got != c.indexSet {
+			t.Errorf("Index(%d).Set: got %s, want %s", c.index, got, c.indexSet)
+		}
+	}
+
+	j := NewJsonFromString(`[1,2,3]`)
+	if got, _ := j.Index(-1).Int(); got != 3 {
+		t.Errorf("Index(-1) = %d, want 3", got)
+	}
+	if got, _ := j.Index(-3).Int(); got != 1 {
+		t.Errorf("Index(-3) = %d, want 1", got)
+	}
+	if j.Index(-4).Exists() {
+		t.Error("Index(-4) should not exist")
+	}
+}
+
+func TestSetIndexGrows(t *testing.T) {
+	for _, data := range []interface{}{[]interface{}{}, List{}} {
+		j := NewJson(data)
+		j.Set(5, "x")
+		if got := compact(j); got != `[null,null,null,null,null,"x"]` {
+			t.Errorf("%T: got %s", data, got)
+		}
+		if got := j.Len(); got != 6 {
+			t.Errorf("%T: Len() = %d, want 6", data, got)
+		}
+		if j.Index(2).Value() != nil {
+			t.Errorf("%T: gap is %v, want nil", data, j.Index(2).Value())
+		}
+
+		j.Set(6+MaxSliceGrow+1, "y")
+		if got := j.Len(); got != 6 {
+			t.Errorf("%T: Set past MaxSliceGrow grew to %d", data, got)
+		}
+		j.Set(6+MaxSliceGrow, "y")
+		if got := j.Len(); got != 7+MaxSliceGrow {
+			t.Errorf("%T: Set at MaxSliceGrow grew to %d", data, got)
+		}
+	}
+}
+
+func TestRename(t *testing.T) {
+	cases := []struct {
+		name, data, oldKey, newKey, want string
+	}{
+		{"move", `{"a":1,"b":2}`, "a", "c", `{"b":2,"c":1}`},
+		{"overwrite existing", `{"a":1,"b":2}`, "a", "b", `{"b":1}`},
+		{"missing old key", `{"a":1}`, "x", "y", `{"a":1}`},
+		{"same key", `{"a":1}`, "a", "a", `{"a":1}`},
+		{"not a map", `[1,2]`, "a", "b", `[1,2]`},
+	}
+	for _, c := range cases {
+		j := NewJsonFromString(c.data)
+		j.Rename(c.oldKey, c.newKey)
+		if got := compact(j); got != c.want {
+			t.Errorf("%s: got %s, want %s", c.name, got, c.want)
+		}
+	}
+
+	j := NewJsonFromString(`{"list":[{"a":1}]}`)
+	j.Get("list").Index(0).Rename("a", "b")
+	if got := compact(j); got != `{"list":[{"b":1}]}` {
+		t.Errorf("nested: got %s", got)
+	}
+}
+
+func TestSelectAll(t *testing.T) {
+	j := NewJsonFromString(`{"":{"id":1},"items":[{"id":2},{"name":"x"}],"id":3}`)
+	nodes := j.SelectAll(func(path string, val interface{}) bool {
+		_, ok := lookupMap("id", val)
+		return ok
+	})
+	var paths []string
+	for _, node := range nodes {
+		paths = append(paths, node.Path())
+	}
+	if got := strings.Join(paths, ","); got != ",,items.0" {
+		t.Fatalf("paths = %q", got)
+	}
+	// 空key下的节点不会被当作根节点
+	nodes[1].Set("id", 10)
+	nodes[2].Set("id", 20)
+	if got := compact(j); got != `{"":{"id":10},"id":3,"items":[{"id":20},{"name":"x"}]}` {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestSetPath(t *testing.T) {
+	j := NewJsonFromString(`{"a":{"l":[1]}}`)
+	if err := j.SetPath("a.l.2.b", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.SetPath("c.0", "x"); err != nil {
+		t.Fatal(err)
+	}
+	if got := compact(j); got != `{"a":{"l":[1,null,{"b":true}]},"c":["x"]}` {
+		t.Errorf("got %s", got)
+	}
+
+	if err := j.SetPath("a.l.100000000", 1); err == nil {
+		t.Error("expected error for an index far past the end")
+	}
+	if err := j.SetPath("d.100000000", 1); err == nil {
+		t.Error("expected error for an index far past the end of a new array")
+	}
+	if got := compact(j); got != `{"a":{"l":[1,null,{"b":true}]},"c":["x"]}` {
+		t.Errorf("failed SetPath changed the tree: %s", got)
+	}
+
+	// 空路径替换当前节点的值，不会创建""这个key
+	if err := j.Get("a").SetPath("", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.SetPath("", map[string]interface{}{"r": j.Get("a").Value()}); err != nil {
+		t.Fatal(err)
+	}
+	if got := compact(j); got != `{"r":1}` {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestIndexOutOfRangeWrite(t *testing.T) {
+	cases := []struct {
+		index int
+		want  string
+	}{
+		{-4, `{"arr":[1,2,3]}`},
+		{-5, `{"arr":[1,2,3]}`},
+		{-100, `{"arr":[1,2,3]}`},
+	}
+	for _, c := range cases {
+		j := NewJsonFromString(`{"arr":[1,2,3]}`)
+		node := j.Get("arr").Index(c.index)
+		if !node.IsNil() || node.Exists() || node.Err() == nil {
+			t.Errorf("Index(%d) should not exist", c.index)
+		}
+		if err := node.SetPath("x", 1); err != nil {
+			t.Fatal(err)
+		}
+		if got := compact(j); got != c.want {
+			t.Errorf("Index(%d).SetPath: got %s, want %s", c.index, got, c.want)
+		}
+	}
+
+	j := NewJsonFromString(`{"m":{"a":1}}`)
+	j.Get("m").Index(0).SetPath("x", 1)
+	if got := compact(j); got != `{"m":{"a":1}}` {
+		t.Errorf("Index on map should not write back: got %s", got)
+	}
+}
+
+func TestFindMissDoesNotWriteBack(t *testing.T) {
+	j := NewJsonFromString(`{"arr":[1,2,3]}`)
+	node := j.Get("arr").Find(func(index int, val interface{}) bool {
+		return false
+	})
+	if !node.IsNil() {
+		t.Fatal("Find should miss")
+	}
+	node.SetPath("x", 1)
+	node.Set("y", 2)
+	if got := compact(j); got != `{"arr":[1,2,3]}` {
+		t.Errorf("write to Find miss changed array: %s", got)
+	}
+}
+
+func TestGetPathMissDoesNotWriteBack(t *testing.T) {
+	for _, path := range []string{"arr.foo", "arr.3", "arr.-1", "arr.foo.bar"} {
+		j := NewJsonFromString(`{"arr":[1,2,3]}`)
+		node := j.GetPath(path)
+		if !node.IsNil() {
+			t.Errorf("GetPath(%s) should be nil", path)
+		}
+		node.SetPath("x", 1)
+		if got := compact(j); got != `{"arr":[1,2,3]}` {
+			t.Errorf("GetPath(%s).SetPath changed array: %s", path, got)
+		}
+	}
+
+	j := NewJsonFromString(`{"arr":[1,2,3]}`)
+	j.Get("arr").Get("foo").SetPath("x", 1)
+	if got := compact(j); got != `{"arr":[1,2,3]}` {
+		t.Errorf("Get on array changed it: %s", got)
+	}
+
+	j.GetPath("m.a").SetPath("b", 1)
+	j.Get("n").SetPath("b", 1)
+	if got := compact(j); got != `{"arr":[1,2,3],"n":{"b":1}}` {
+		t.Errorf("missing map key should still be created: %s", got)
+	}
+}
+
+func TestPointerMissDoesNotWriteBack(t *testing.T) {
+	for _, p := range []string{"/arr/7", "/arr/x", "/arr/-", "/arr/01"} {
+		j := NewJsonFromString(`{"arr":[1,2,3]}`)
+		node := j.Pointer(p)
+		if !node.IsNil() {
+			t.Errorf("Pointer(%s) should be nil", p)
+		}
+		node.SetPath("x", 1)
+		if got := compact(j); got != `{"arr":[1,2,3]}` {
+			t.Errorf("Pointer(%s).SetPath changed array: %s", p, got)
+		}
+	}
+}
+
+func TestUpdateConcurrentWithSet(t *testing.T) {
+	j := NewJsonFromString(`{"arr":[1,2,3],"n":0}`)
+	arr := j.Get("arr")
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 100; i++ {
+			j.Set("n", i)
+		}
+		done <- true
+	}()
+	for i := 0; i < 100; i++ {
+		arr.Update(func(index int, val interface{}) bool {
+			return index == 0
+		}, func(val interface{}) interface{} {
+			return i
+		})
+	}
+	<-done
+	if got := compact(j); got != `{"arr":[99,2,3],"n":99}` {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestConcurrentReadWrite(t *testing.T) {
+	j := NewJsonFromString(`{"arr":[],"m":{"n":0}}`)
+	arr := j.Get("arr")
+	m := j.Get("m")
+	done := make(chan bool)
+	for w := 0; w < 2; w++ {
+		go func(w int) {
+			for i := 0; i < 200; i++ {
+				arr.Append(i)
+				m.Set("n", i)
+				j.SetPath("p.q", i)
+			}
+			done <- true
+		}(w)
+	}
+	for r := 0; r < 4; r++ {
+		go func() {
+			for i := 0; i < 200; i++ {
+				arr.Kind()
+				arr.IsSlice()
+				arr.Len()
+				m.IsMap()
+				m.Get("n").Int()
+				m.Get("n").Float64()
+				m.Get("n").Number()
+				m.GetInt("n")
+				j.GetPath("p.q").Value()
+				j.GetPath("p.q").Exists()
+				j.Get("x").IsNil()
+				j.Type()
+				_ = j.String()
+			}
+			done <- true
+		}()
+	}
+	for i := 0; i < 6; i++ {
+		<-done
+	}
+	if arr.Len() != 400 {
+		t.Errorf("got %d elements, want 400", arr.Len())
+	}
+}
+
+func TestCallbackReentrant(t *testing.T) {
+	j := NewJsonFromString(`{"arr":[3,1,2],"m":{"a":1}}`)
+	arr := j.Get("arr")
+
+	// 回调在锁外调用，可以访问同一棵树
+	arr.Sort(func(a, b interface{}) bool {
+		j.Get("m").Len()
+		return compareValues(a, b) < 0
+	})
+	arr.Update(func(index int, val interface{}) bool {
+		return j.GetPath("arr.0").Exists() && index == 0
+	}, func(val interface{}) interface{} {
+		return arr.Len()
+	})
+	j.Get("m").RangeMap(func(key string, val interface{}) bool {
+		j.Get("m").Set("b", 2)
+		return true
+	})
+	j.Walk(func(path string, value interface{}) bool {
+		j.Set("walked", true)
+		return false
+	})
+	if got := compact(j); got != `{"arr":[3,2,3],"m":{"a":1,"b":2},"walked":true}` {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestConcurrentCallbacks(t *testing.T) {
+	j := NewJsonFromString(`{"arr":[1,2,3],"m":{"a":1}}`)
+	arr := j.Get("arr")
+	m := j.Get("m")
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 200; i++ {
+			arr.Append(i)
+			m.Set("b", i)
+		}
+		done <- true
+	}()
+	go func() {
+		for i := 0; i < 200; i++ {
+			arr.Filter(func(index int, val interface{}) bool { return true })
+			arr.MapSlice(func(index int, val interface{}) interface{} { return val })
+			arr.FindIndex(func(index int, val interface{}) bool { return false })
+			m.RangeMapSorted(func(key string, val interface{}) bool { return true })
+			j.RangeDeep(func(path string, val interface{}) bool { return true })
+			j.SelectAll(func(path string, val interface{}) bool { return path == "m.a" })
+			j.GetPath("m.a").Int()
+			j.Pointer("/arr/0").Int()
+		}
+		done <- true
+	}()
+	<-done
+	<-done
+}
+
+func TestMergeNil(t *testing.T) {
+	j := NewJsonFromString(`{"a":{"b":1}}`)
+	var other *GoJson
+	cases := []struct {
+		name  string
+		merge func() *GoJson
+	}{
+		{"Merge(nil)", func() *GoJson { return j.Merge(other) }},
+		{"MergeDeep(nil)", func() *GoJson { return j.MergeDeep(other) }},
+		{"Merge(array)", func() *GoJson { return j.Merge(NewArray()) }},
+		{"MergeDeep(array)", func() *GoJson { return j.MergeDeep(NewArray()) }},
+	}
+	for _, c := range cases {
+		if got := c.merge(); got != j || compact(j) != `{"a":{"b":1}}` {
+			t.Errorf("%s changed json: %s", c.name, compact(j))
+		}
+	}
+}
+
+func TestApplyMergePatchNil(t *testing.T) {
+	j := NewJsonFromString(`{"a":1}`)
+	var patch *GoJson
+	if got := j.ApplyMergePatch(patch); got != j || compact(j) != `{"a":1}` {
+		t.Errorf("ApplyMergePatch(nil) changed json: %s", compact(j))
+	}
+}
+
+func TestMergeArrayByKeyNil(t *testing.T) {
+	j := NewJsonFromString(`[{"id":1}]`)
+	var other *GoJson
+	if got := j.MergeArrayByKey(other, "id"); got != j || compact(j) != `[{"id":1}]` {
+		t.Errorf("MergeArrayByKey(nil) changed json: %s", compact(j))
+	}
+}
+
+func TestConcatNil(t *testing.T) {
+	j := NewJsonFromString(`[1,2]`)
+	var nilJson *GoJson
+	for _, other := range []interface{}{nil, nilJson, NewJsonFromData(nil)} {
+		if got := compact(j.Concat(other)); got != `[1,2]` {
+			t.Errorf("Concat(%#v) = %s", other, got)
+		}
+	}
+	if got := compact(j.Concat(NewJsonFromString(`[3]`))); got != `[1,2,3]` {
+		t.Errorf("Concat = %s", got)
+	}
+}
+
+func TestNewJsonTrailingData(t *testing.T) {
+	cases := []struct {
+		input string
+		ok    bool
+	}{
+		{`{"a":1}`, true},
+		{" {\"a\":1} \n\t", true},
+		{`[1,2]`, true},
+		{`{"a":1} garbage`, false},
+		{`{"a":1}}`, false},
+		{`{"a":1}{"b":2}`, false},
+		{`1 2`, false},
+		{``, false},
+	}
+	for _, c := range cases {
+		_, err := NewJsonFromStringE(c.input)
+		if (err == nil) != c.ok {
+			t.Errorf("NewJsonFromStringE(%q) error = %v", c.input, err)
+		}
+		_, err = NewJsonFromBytesE([]byte(c.input))
+		if (err == nil) != c.ok {
+			t.Errorf("NewJsonFromBytesE(%q) error = %v", c.input, err)
+		}
+		for _, opts := range []Options{{}, {PreserveOrder: true}} {
+			_, err = NewJsonFromBytesWithOptions([]byte(c.input), opts)
+			if (err == nil) != c.ok {
+				t.Errorf("NewJsonFromBytesWithOptions(%q, %+v) error = %v", c.input, opts, err)
+			}
+		}
+	}
+
+	// 不返回error的构造函数保持原有行为，忽略第一个json值之后的数据
+	for _, j := range []*GoJson{NewJsonFromString(`{"a":1} garbage`), NewJsonFromBytes([]byte(`{"a":1}{"b":2}`))} {
+		if got := compact(j); got != `{"a":1}` || j.ParseError() != nil {
+			t.Errorf("got %s, %v", got, j.ParseError())
+		}
+	}
+	if j := NewJsonFromString(`{"a":`); j.ParseError() == nil {
+		t.Error("ParseError should report the parse failure")
+	}
+}
+
+func TestStrictDuplicateKeys(t *testing.T) {
+	src := `{"a":1,"b":{"c":1,"c":2},"a":3,"l":[{"x.y":1,"x.y":2}],"s":"{\"a\":1,\"a\":2}"}`
+	_, err := NewJsonFromBytesWithOptions([]byte(src), Options{StrictDuplicateKeys: true})
+	if err == nil || !strings.HasSuffix(err.Error(), `duplicate keys: b.c, a, l.0.x\.y`) {
+		t.Errorf("error = %v", err)
+	}
+
+	// 默认保持encoding/json的行为，后出现的值覆盖前面的
+	j, err := NewJsonFromBytesWithOptions([]byte(src), Options{})
+	if n, _ := j.GetInt("a"); err != nil || n != 3 {
+		t.Errorf("got %s, %v", compact(j), err)
+	}
+	if _, err := NewJsonFromBytesWithOptions([]byte(`{"a":{"b":1},"c":{"b":2}}`), Options{StrictDuplicateKeys: true}); err != nil {
+		t.Errorf("same key in different objects: %v", err)
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	if _, err := NewJsonFromBytesWithOptions([]byte(`{"a":[{"b":1}]}`), Options{MaxDepth: 3}); err != nil {
+		t.Errorf("depth 3: %v", err)
+	}
+	j, err := NewJsonFromBytesWithOptions([]byte(`{"a":[{"b":[]}]}`), Options{MaxDepth: 3})
+	if err == nil || !strings.Contains(err.Error(), "exceeded max depth 3 at offset 11") {
+		t.Errorf("depth 4: %v", err)
+	}
+	if !j.IsNil() || j.ParseError() != err {
+		t.Errorf("failed parse returned %s, %v", compact(j), j.ParseError())
+	}
+
+	// 字符串中的括号不计入深度
+	if _, err := NewJsonFromBytesWithOptions([]byte(`{"s":"[[[{\"]]]"}`), Options{MaxDepth: 1}); err != nil {
+		t.Errorf("brackets in string: %v", err)
+	}
+	deep := strings.Repeat("[", 100000) + strings.Repeat("]", 100000)
+	if _, err := NewJsonFromBytesWithOptions([]byte(deep), Options{MaxDepth: 64}); err == nil {
+		t.Error("expected error for deeply nested input")
+	}
+}
+
+func TestCompact(t *testing.T) {
+	j := NewJsonFromString(`{ "s" : "a b\\\" c", "l" : [ 1, 2.50 ] }`)
+	if got := string(j.Compact()); got != `{"l":[1,2.50],"s":"a b\\\" c"}` {
+		t.Errorf("got %s", got)
+	}
+	// json.RawMessage中的空白原样出现在编码结果中，需要去掉
+	j = NewJsonFromData(map[string]interface{}{"r": sysjson.RawMessage(`[ 1 , "x y" ]`)})
+	if got := string(j.Compact()); got != `{"r":[1,"x y"]}` {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestNewJsonFromStructError(t *testing.T) {
+	j := NewJsonFromStruct(struct {
+		C chan int
+	}{})
+	if !j.IsNil() || j.ParseError() == nil {
+		t.Errorf("got %v, ParseError %v", j.Value(), j.ParseError())
+	}
+
+	j = NewJsonFromStruct(struct {
+		Name string `json:"name"`
+	}{"x"})
+	if got := compact(j); got != `{"name":"x"}` || j.ParseError() != nil {
+		t.Errorf("got %s, %v", got, j.ParseError())
+	}
+}
+
+func TestAppendBytes(t *testing.T) {
+	j := NewJsonFromString(`{"b":[1,2.50],"a":"<x>"}`)
+	dst := make([]byte, 0, 256)
+	dst = append(dst, "prefix:"...)
+	got, err := j.AppendBytes(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "prefix:" + string(j.Bytes()); string(got) != want {
+		t.Errorf("AppendBytes = %s, want %s", got, want)
+	}
+	if &got[0] != &dst[0] {
+		t.Error("AppendBytes should reuse dst when it has enough capacity")
+	}
+
+	// 与Bytes的输出逐字节相同
+	j = NewJsonFromString(`{"z":{"y":[{"x":"<&>"},null,true,1e300,-0.5,"\u2028é"]},"a":{},"b":[],"k\"<":1}`)
+	j.Set("dict", Dict{"b": List{1, nil}, "a": map[string]interface{}(nil)})
+	j.Set("list", List{[]interface{}(nil), 3.25, sysjson.Number("12345678901234567890")})
+	ordered := NewOrderedDict()
+	ordered.Set("b", 1)
+	ordered.Set("a", []interface{}{"<"})
+	j.Set("ordered", ordered)
+	for _, escape := range []bool{true, false} {
+		EscapeHTML = escape
+		got, err := j.AppendBytes(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := string(j.Bytes()); string(got) != want {
+			t.Errorf("EscapeHTML=%v: AppendBytes = %s, want %s", escape, got, want)
+		}
+	}
+	EscapeHTML = true
+}
+
+func TestNavigateErr(t *testing.T) {
+	j := NewJsonFromString(`{"a":[{"b":1},2,3],"s":"x"}`)
+	cases := []struct {
+		node *GoJson
+		want string
+	}{
+		{j.Get("x"), "path x: not found"},
+		{j.Get("a").Index(5), "path a[5]: not found"},
+		{j.Get("a").Index(-4), "path a[-4]: not found"},
+		{NewJsonFromString(`[1]`).Index(-5), "path [-5]: not found"},
+		{j.Get("a").Index(1).Get("b"), "path a[1].b: a[1] is not a map"},
+		{j.Get("s").Index(0), "path s[0]: s is not an array"},
+		{j.Index(0), "path [0]: root is not an array"},
+		// 第一次失败的原因沿用到后续节点
+		{j.Get("x").Get("y").Index(1), "path x: not found"},
+		{j.Get("a").Index(1).Get("b").Get("c"), "path a[1].b: a[1] is not a map"},
+	}
+	for _, c := range cases {
+		err := c.node.Err()
+		if err == nil || err.Error() != c.want {
+			t.Errorf("got %v, want %s", err, c.want)
+		}
+	}
+	if err := j.Get("a").Index(0).Get("b").Err(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestNavigateErrAfterRelease(t *testing.T) {
+	EnablePool = true
+	defer func() { EnablePool = false }()
+	j := NewJsonFromString(`{"a":{"b":1}}`)
+
+	a := j.Get("a")
+	x := a.Get("x")
+	y := x.Get("y")
+	x.Release()
+	a.Release()
+	// 被回收的节点可能被复用
+	j.Get("a").Get("b")
+	if err := y.Err(); err == nil || err.Error() != "path a.x: not found" {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestGetMissNoAlloc(t *testing.T) {
+	EnablePool = true
+	defer func() { EnablePool = false }()
+	j := NewJsonFromString(`{"a":{"b":1},"l":[1]}`)
+	a := j.Get("a")
+	l := j.Get("l")
+	allocs := testing.AllocsPerRun(100, func() {
+		a.Get("missing").Release()
+		l.Index(5).Release()
+	})
+	if allocs != 0 {
+		t.Errorf("Get/Index miss allocates %v times, want 0", allocs)
+	}
+}
+
+func TestSetNumber(t *testing.T) {
+	j := NewObject()
+	j.SetNumber("big", "12345678901234567890123")
+	j.SetNumber("frac", "-1.50e3")
+	for _, bad := range []sysjson.Number{"", "abc", "1e", "01", "+1", `"1"`, "true", "NaN"} {
+		j.SetNumber("bad", bad)
+	}
+	if got := compact(j); got != `{"big":12345678901234567890123,"frac":-1.50e3}` {
+		t.Errorf("got %s", got)
+	}
+
+	// ToString对浮点数的格式保持不变
+	if got := ToString(1e-7); got != "1e-07" {
+		t.Errorf("ToString(1e-7) = %s", got)
+	}
+}
@@ -0,0 +1,34 @@
+//go:build go1.23
+// +build go1.23
+
+package gojson
+
+import "iter"
+
+// All 返回数组元素的迭代器，可以使用 for i, item := range j.All() 遍历，当json不为slice时不产生任何元素。
+// item与Index得到的节点相同，对其修改会同步到当前对象。与RangeSlice一样遍历期间不持有锁，循环中可以修改数组
+func (j *GoJson) All() iter.Seq2[int, *GoJson] {
+	return func(yield func(int, *GoJson) bool) {
+		for i := 0; i < j.Len(); i++ {
+			if !yield(i, j.Index(i)) {
+				return
+			}
+		}
+	}
+}
+
+// Entries 返回k-v结构的迭代器，可以使用 for key, item := range j.Entries() 遍历，顺序不固定，当json不为map时不产生任何元素。
+// item与Get得到的节点相同，对其修改会同步到当前对象。遍历期间不持有锁，循环中删除的key不会再出现
+func (j *GoJson) Entries() iter.Seq2[string, *GoJson] {
+	return func(yield func(string, *GoJson) bool) {
+		for _, key := range j.Keys() {
+			item := j.Get(key)
+			if !item.Exists() {
+				continue
+			}
+			if !yield(key, item) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+//go:build go1.23
+// +build go1.23
+
+package gojson
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAll(t *testing.T) {
+	j := NewJsonFromString(`{"l":[{"n":1},{"n":2},{"n":3}]}`)
+	l := j.Get("l")
+	for i, item := range l.All() {
+		item.Set("i", i)
+		if i == 1 {
+			break
+		}
+	}
+	if got := compact(j); got != `{"l":[{"i":0,"n":1},{"i":1,"n":2},{"n":3}]}` {
+		t.Errorf("got %s", got)
+	}
+
+	// 遍历期间不持有锁，循环中追加的元素也会被遍历到
+	count := 0
+	for range l.All() {
+		if count == 0 {
+			l.Append(4)
+		}
+		count++
+	}
+	if count != 4 {
+		t.Errorf("visited %d elements, want 4", count)
+	}
+
+	for range j.All() {
+		t.Error("All on a map should yield nothing")
+	}
+}
+
+func TestEntries(t *testing.T) {
+	j := parseOrdered(t, `{"a":{"n":1},"b":{"n":2},"c":3}`)
+	var keys []string
+	for key, item := range j.Entries() {
+		keys = append(keys, key)
+		if item.IsMap() {
+			item.Set("seen", true)
+		}
+		j.Remove("b")
+	}
+	// OrderedDict按插入顺序遍历，循环中删除的key不会再出现
+	if got := strings.Join(keys, ","); got != "a,c" {
+		t.Errorf("keys = %s", got)
+	}
+	if got := compact(j); got != `{"a":{"n":1,"seen":true},"c":3}` {
+		t.Errorf("got %s", got)
+	}
+
+	j = NewJsonFromString(`{"x":1,"y":2}`)
+	got := map[string]int{}
+	for key, item := range j.Entries() {
+		got[key], _ = item.Int()
+	}
+	if len(got) != 2 || got["x"] != 1 || got["y"] != 2 {
+		t.Errorf("got %v", got)
+	}
+
+	for range NewJsonFromString(`[1]`).Entries() {
+		t.Error("Entries on an array should yield nothing")
+	}
+}
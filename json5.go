@@ -0,0 +1,262 @@
+package gojson
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// relaxedParser 将JSON5格式的文本转换为标准json
+type relaxedParser struct {
+	src []byte
+	pos int
+	out bytes.Buffer
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || c >= '0' && c <= '9'
+}
+
+// skipSpace 跳过空白字符和注释，返回下一个有效字符的位置
+func (p *relaxedParser) skipSpace(pos int) (int, error) {
+	for pos < len(p.src) {
+		switch c := p.src[pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			pos++
+		case c == '/' && pos+1 < len(p.src) && p.src[pos+1] == '/':
+			for pos < len(p.src) && p.src[pos] != '\n' {
+				pos++
+			}
+		case c == '/' && pos+1 < len(p.src) && p.src[pos+1] == '*':
+			end := bytes.Index(p.src[pos+2:], []byte("*/"))
+			if end < 0 {
+				return pos, fmt.Errorf("unterminated comment at %d", pos)
+			}
+			pos += end + 4
+		default:
+			return pos, nil
+		}
+	}
+	return pos, nil
+}
+
+// writeString 转换以quote开头的字符串，单引号字符串转换为双引号字符串
+func (p *relaxedParser) writeString(quote byte) error {
+	start := p.pos
+	p.pos++
+	p.out.WriteByte('"')
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			p.out.WriteByte('"')
+			return nil
+		case c == '\\' && p.pos+1 < len(p.src):
+			next := p.src[p.pos+1]
+			switch next {
+			case '\'':
+				p.out.WriteByte('\'')
+			case '\n':
+				// 行尾的 \ 表示字符串跨行
+			case '\r':
+				if p.pos+2 < len(p.src) && p.src[p.pos+2] == '\n' {
+					p.pos++
+				}
+			default:
+				p.out.WriteByte(c)
+				p.out.WriteByte(next)
+			}
+			p.pos += 2
+		case c == '"':
+			p.out.WriteString(`\"`)
+			p.pos++
+		default:
+			p.out.WriteByte(c)
+			p.pos++
+		}
+	}
+	return fmt.Errorf("unterminated string at %d", start)
+}
+
+// writeNumber 转换数字，支持十六进制、开头的+号以及省略整数或小数部分的小数，如 0x1F、+1、.5、5.
+func (p *relaxedParser) writeNumber() error {
+	start := p.pos
+	for p.pos < len(p.src) && (isIdentPart(p.src[p.pos]) || strings.IndexByte("+-.", p.src[p.pos]) >= 0) {
+		if (p.src[p.pos] == '+' || p.src[p.pos] == '-') && p.pos > start {
+			prev := p.src[p.pos-1]
+			if prev != 'e' && prev != 'E' || strings.HasPrefix(strings.ToLower(string(p.src[start:p.pos])), "0x") {
+				break
+			}
+		}
+		p.pos++
+	}
+	token := string(p.src[start:p.pos])
+	invalid := fmt.Errorf("invalid number %s at %d", token, start)
+
+	sign := ""
+	switch {
+	case strings.HasPrefix(token, "-"):
+		sign, token = "-", token[1:]
+	case strings.HasPrefix(token, "+"):
+		token = token[1:]
+	}
+	if strings.HasPrefix(token, "0x") || strings.HasPrefix(token, "0X") {
+		n, err := strconv.ParseUint(token[2:], 16, 64)
+		if err != nil {
+			return invalid
+		}
+		token = strconv.FormatUint(n, 10)
+	}
+	if strings.HasPrefix(token, ".") {
+		// 省略整数部分时小数点后必须有数字，单独的 . 不是数字
+		if len(token) < 2 || token[1] < '0' || token[1] > '9' {
+			return invalid
+		}
+		token = "0" + token
+	}
+	if i := strings.IndexByte(token, '.'); i >= 0 && (i+1 == len(token) || token[i+1] == 'e' || token[i+1] == 'E') {
+		token = token[:i] + token[i+1:]
+	}
+	// 只有符号、不完整的指数等情况转换后仍不是json数字，不能原样输出
+	if !isNumberString(sign + token) {
+		return invalid
+	}
+	p.out.WriteString(sign + token)
+	return nil
+}
+
+// convert 逐个处理token，去掉注释和多余的逗号，为key加上引号
+func (p *relaxedParser) convert() error {
+	pendingComma := false
+	for {
+		pos, err := p.skipSpace(p.pos)
+		if err != nil {
+			return err
+		}
+		// 跳过的空白和注释替换为一个空格，否则相邻的两个值会被拼接在一起，如 [1 2] 变为 [12]
+		if pos > p.pos && p.out.Len() > 0 {
+			p.out.WriteByte(' ')
+		}
+		p.pos = pos
+		if p.pos >= len(p.src) {
+			if pendingComma {
+				p.out.WriteByte(',')
+			}
+			return nil
+		}
+
+		c := p.src[p.pos]
+		if c == ',' {
+			if pendingComma {
+				return fmt.Errorf("unexpected , at %d", p.pos)
+			}
+			pendingComma = true
+			p.pos++
+			continue
+		}
+		if pendingComma && c != '}' && c != ']' {
+			p.out.WriteByte(',')
+		}
+		pendingComma = false
+
+		switch {
+		case c == '"' || c == '\'':
+			if err := p.writeString(c); err != nil {
+				return err
+			}
+		case c >= '0' && c <= '9' || c == '-' || c == '+' || c == '.':
+			if err := p.writeNumber(); err != nil {
+				return err
+			}
+		case isIdentStart(c):
+			start := p.pos
+			for p.pos < len(p.src) && isIdentPart(p.src[p.pos]) {
+				p.pos++
+			}
+			ident := string(p.src[start:p.pos])
+			next, err := p.skipSpace(p.pos)
+			if err != nil {
+				return err
+			}
+			if next < len(p.src) && p.src[next] == ':' {
+				p.out.WriteString(strconv.Quote(ident))
+				continue
+			}
+			switch ident {
+			case "true", "false", "null":
+				p.out.WriteString(ident)
+			default:
+				return fmt.Errorf("unsupported value %s at %d", ident, start)
+			}
+		default:
+			p.out.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// NewJsonFromJSON5 从JSON5格式的bytes创建GoJson对象，解析失败时返回error。
+// 支持 // 和 /* */ 注释、末尾多余的逗号、不加引号的key、单引号字符串、十六进制数字、
+// 开头的+号以及 .5、5. 形式的小数。不支持Infinity和NaN。解析结果与标准json解析得到的对象完全相同
+func NewJsonFromJSON5(b []byte) (*GoJson, error) {
+	p := &relaxedParser{src: b}
+	if err := p.convert(); err != nil {
+		return parseFailed(err)
+	}
+	return NewJsonFromBytesE(p.out.Bytes())
+}
+
+// stripComments 将 // 和 /* */ 注释替换为空格，字符串中的注释标记保持不变。换行符会保留，解析出错时位置与原文一致
+func stripComments(b []byte) ([]byte, error) {
+	result := make([]byte, len(b))
+	copy(result, b)
+	inString := false
+	for i := 0; i < len(result); i++ {
+		c := result[i]
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+		switch {
+		case c == '"':
+			inString = true
+		case c == '/' && i+1 < len(result) && result[i+1] == '/':
+			for ; i < len(result) && result[i] != '\n'; i++ {
+				result[i] = ' '
+			}
+		case c == '/' && i+1 < len(result) && result[i+1] == '*':
+			end := bytes.Index(result[i+2:], []byte("*/"))
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment at %d", i)
+			}
+			for stop := i + end + 4; i < stop; i++ {
+				if result[i] != '\n' {
+					result[i] = ' '
+				}
+			}
+			i--
+		}
+	}
+	return result, nil
+}
+
+// NewJsonFromJSONC 从JSONC格式(带 // 和 /* */ 注释的json，如tsconfig.json)的bytes创建GoJson对象，解析失败时返回error。
+// 字符串中的 // 和 /* 不会被当作注释。除注释外须为标准json，需要末尾逗号等更宽松的语法时使用NewJsonFromJSON5
+func NewJsonFromJSONC(b []byte) (*GoJson, error) {
+	stripped, err := stripComments(b)
+	if err != nil {
+		return parseFailed(err)
+	}
+	return NewJsonFromBytesE(stripped)
+}
@@ -0,0 +1,105 @@
+package gojson
+
+import "testing"
+
+func TestNewJsonFromJSON5(t *testing.T) {
+	src := `
+// 注释
+{
+	name: 'gojson', /* 块注释 */
+	"quoted": "it's",
+	list: [1, 2, 3,],
+	hex: 0x1F,
+	plus: +1,
+	half: .5,
+	five: 5.,
+	exp: 5.e2,
+	neg: -0x10,
+	quote: 'say "hi"',
+	url: "http://x//y",
+	nested: {a: null, b: true,},
+}`
+	j, err := NewJsonFromJSON5([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"exp":5e2,"five":5,"half":0.5,"hex":31,"list":[1,2,3],"name":"gojson","neg":-16,` +
+		`"nested":{"a":null,"b":true},"plus":1,"quote":"say \"hi\"","quoted":"it's","url":"http://x//y"}`
+	if got := compact(j); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+
+	strict := NewJsonFromString(want)
+	if !j.Equals(strict) {
+		t.Error("JSON5 result differs from the strictly parsed one")
+	}
+}
+
+func TestNewJsonFromJSON5Invalid(t *testing.T) {
+	for _, src := range []string{
+		"[1 2]",
+		"[1\n2]",
+		"[1/* */2]",
+		`{a:1 b:2}`,
+		"[+]",
+		"[-]",
+		"[.]",
+		"[.e1]",
+		"[1e]",
+		"[1e+]",
+		"[01]",
+		"[0xZZ]",
+		"[Infinity]",
+		"[1,,2]",
+		"[1] [2]",
+		"{a:'x}",
+		"[1 /* x",
+	} {
+		if j, err := NewJsonFromJSON5([]byte(src)); err == nil {
+			t.Errorf("%s: expected error, got %s", src, compact(j))
+		}
+	}
+}
+
+func TestNewJsonFromJSONC(t *testing.T) {
+	src := `{
+	// 行注释
+	"url": "http://example.com/*not a comment*/", /* 块注释 */
+	"escaped": "a\"//b",
+	"n": 1 // 末尾的注释
+}
+// 文件末尾的注释`
+	j, err := NewJsonFromJSONC([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := j.GetString("url"); got != "http://example.com/*not a comment*/" {
+		t.Errorf("url = %q", got)
+	}
+	if got := j.GetString("escaped"); got != `a"//b` {
+		t.Errorf("escaped = %q", got)
+	}
+
+	// 除注释外必须是标准json
+	if _, err := NewJsonFromJSONC([]byte(`{"a":1,}`)); err == nil {
+		t.Error("trailing comma should be rejected")
+	}
+	if _, err := NewJsonFromJSONC([]byte(`{"a":1} /* x`)); err == nil {
+		t.Error("unterminated comment should be rejected")
+	}
+	if _, err := NewJsonFromJSONC([]byte(`{"a":1} {"b":2}`)); err == nil {
+		t.Error("trailing data should be rejected")
+	}
+}
+
+func TestStripCommentsKeepsOffsets(t *testing.T) {
+	src := "/* a\nb */{\"s\":\"/*x*/\"} // c\n"
+	stripped, err := stripComments([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 注释替换为空格，保留换行，出错时的位置与原文一致
+	if want := "    \n    {\"s\":\"/*x*/\"}     \n"; string(stripped) != want {
+		t.Errorf("got %q, want %q", stripped, want)
+	}
+}
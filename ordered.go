@@ -0,0 +1,199 @@
+package gojson
+
+import (
+	"bytes"
+	sysjson "encoding/json"
+	"fmt"
+	"log"
+	"sort"
+)
+
+// OrderedDict 记住key插入顺序的k-v结构，编码时按插入顺序输出。
+// 通过Options的PreserveOrder解析得到，也可以通过NewOrderedDict创建后作为GoJson的数据。
+// 已存在的key重新设置时位置不变，删除后重新设置的key排在最后
+type OrderedDict struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func NewOrderedDict() *OrderedDict {
+	return &OrderedDict{values: make(map[string]interface{})}
+}
+
+// Set 设置key对应的值，key不存在时添加到最后
+func (d *OrderedDict) Set(key string, val interface{}) {
+	if _, ok := d.values[key]; !ok {
+		d.keys = append(d.keys, key)
+	}
+	d.values[key] = val
+}
+
+// Get 返回key对应的值，bool表示key是否存在
+func (d *OrderedDict) Get(key string) (interface{}, bool) {
+	val, ok := d.values[key]
+	return val, ok
+}
+
+// Delete 删除key
+func (d *OrderedDict) Delete(key string) {
+	if _, ok := d.values[key]; !ok {
+		return
+	}
+	delete(d.values, key)
+	for i, k := range d.keys {
+		if k == key {
+			d.keys = append(d.keys[:i], d.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// rename 将oldKey改为newKey，位置不变，newKey已存在时先删除。oldKey不存在时什么都不会发生
+func (d *OrderedDict) rename(oldKey, newKey string) {
+	val, ok := d.values[oldKey]
+	if !ok || oldKey == newKey {
+		return
+	}
+	d.Delete(newKey)
+	for i, key := range d.keys {
+		if key == oldKey {
+			d.keys[i] = newKey
+			break
+		}
+	}
+	delete(d.values, oldKey)
+	d.values[newKey] = val
+}
+
+// Len 返回key的个数
+func (d *OrderedDict) Len() int {
+	return len(d.values)
+}
+
+// Keys 按插入顺序返回所有的key
+func (d *OrderedDict) Keys() []string {
+	result := make([]string, 0, len(d.values))
+	seen := make(map[string]bool, len(d.values))
+	for _, key := range d.keys {
+		if _, ok := d.values[key]; ok && !seen[key] {
+			seen[key] = true
+			result = append(result, key)
+		}
+	}
+	if len(result) < len(d.values) {
+		extra := make([]string, 0, len(d.values)-len(result))
+		for key := range d.values {
+			if !seen[key] {
+				extra = append(extra, key)
+			}
+		}
+		sort.Strings(extra)
+		result = append(result, extra...)
+	}
+	return result
+}
+
+// orderedKeys 按编码输出的顺序返回k-v结构的key：*OrderedDict按插入顺序，其余按字典序
+func orderedKeys(m interface{}) []string {
+	if d, ok := m.(*OrderedDict); ok {
+		return d.Keys()
+	}
+	jsonMap, _ := toMap(m)
+	keys := make([]string, 0, len(jsonMap))
+	for key := range jsonMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// MarshalJSON 实现json.Marshaler，按插入顺序输出key
+func (d *OrderedDict) MarshalJSON() ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	buffer.WriteByte('{')
+	for i, key := range d.Keys() {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		keyBytes, err := marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(keyBytes)
+		buffer.WriteByte(':')
+		valBytes, err := marshal(d.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(valBytes)
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
+
+// UnmarshalJSON 实现json.Unmarshaler，嵌套的k-v结构同样解析为OrderedDict，数字解析为json.Number
+func (d *OrderedDict) UnmarshalJSON(b []byte) error {
+	decoder := sysjson.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	val, err := decodeOrdered(decoder)
+	if err != nil {
+		return err
+	}
+	od, ok := val.(*OrderedDict)
+	if !ok {
+		return fmt.Errorf("%v is not map", val)
+	}
+	*d = *od
+	return nil
+}
+
+func (d *OrderedDict) String() string {
+	result, err := d.MarshalJSON()
+	if err != nil {
+		log.Println("json to string error", err)
+		return ""
+	}
+	return string(result)
+}
+
+// decodeOrdered 逐个token读取一个完整的json值，k-v结构解析为OrderedDict。重复的key保留第一次出现的位置和最后一次的值
+func decodeOrdered(decoder *sysjson.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case sysjson.Delim('{'):
+		d := NewOrderedDict()
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, _ := keyToken.(string)
+			val, err := decodeOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			d.Set(key, val)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return d, nil
+	case sysjson.Delim('['):
+		l := make([]interface{}, 0)
+		for decoder.More() {
+			val, err := decodeOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, val)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return l, nil
+	}
+	return token, nil
+}
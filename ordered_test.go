@@ -0,0 +1,97 @@
+package gojson
+
+import "testing"
+
+// parseOrdered 按PreserveOrder解析s
+func parseOrdered(t *testing.T, s string) *GoJson {
+	t.Helper()
+	j, err := NewJsonFromBytesWithOptions([]byte(s), Options{PreserveOrder: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return j
+}
+
+func TestPreserveOrderRoundTrip(t *testing.T) {
+	src := `{"c":1,"a":{"z":true,"b":null},"b":[{"y":1,"x":2}]}`
+	j := parseOrdered(t, src)
+	if got := compact(j); got != src {
+		t.Errorf("got %s", got)
+	}
+	if got := compact(j.Clone()); got != src {
+		t.Errorf("Clone: got %s", got)
+	}
+	got, err := j.AppendBytes(nil)
+	if err != nil || string(got) != src {
+		t.Errorf("AppendBytes: got %s, %v", got, err)
+	}
+}
+
+func TestPreserveOrderMutations(t *testing.T) {
+	src := `{"a":1,"b":2,"c":3}`
+
+	j := parseOrdered(t, src)
+	j.Rename("a", "z").Set("a", 9)
+	if got := compact(j); got != `{"z":1,"b":2,"c":3,"a":9}` {
+		t.Errorf("Rename then Set: got %s", got)
+	}
+
+	j = parseOrdered(t, src)
+	j.Rename("a", "c")
+	if got := compact(j); got != `{"c":1,"b":2}` {
+		t.Errorf("Rename onto existing key: got %s", got)
+	}
+
+	j = parseOrdered(t, src)
+	j.Remove("a")
+	j.Set("a", 1)
+	if got := compact(j); got != `{"b":2,"c":3,"a":1}` {
+		t.Errorf("Remove then Set: got %s", got)
+	}
+
+	j = parseOrdered(t, src)
+	j.ApplyMergePatch(NewJsonFromString(`{"b":null}`))
+	j.ApplyMergePatch(NewJsonFromString(`{"b":4}`))
+	if got := compact(j); got != `{"a":1,"c":3,"b":4}` {
+		t.Errorf("ApplyMergePatch: got %s", got)
+	}
+
+	j = parseOrdered(t, src)
+	j.Merge(NewJsonFromString(`{"e":5,"d":4,"a":0}`))
+	if got := compact(j); got != `{"a":0,"b":2,"c":3,"d":4,"e":5}` {
+		t.Errorf("Merge: got %s", got)
+	}
+
+	j = parseOrdered(t, `{"n":{"b":1,"a":2},"m":0}`)
+	j.MergeDeep(parseOrdered(t, `{"n":{"d":3,"c":4}}`))
+	if got := compact(j); got != `{"n":{"b":1,"a":2,"d":3,"c":4},"m":0}` {
+		t.Errorf("MergeDeep: got %s", got)
+	}
+
+	j = parseOrdered(t, src)
+	err := j.ApplyPatch([]PatchOp{
+		{Op: "remove", Path: "/a"},
+		{Op: "add", Path: "/a", Value: 7},
+		{Op: "move", From: "/b", Path: "/d"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := compact(j); got != `{"c":3,"a":7,"d":2}` {
+		t.Errorf("ApplyPatch: got %s", got)
+	}
+
+	j = parseOrdered(t, `{"x":{"a":1,"b":2},"y":{}}`)
+	if err := j.MoveSubtree("x.a", "y.a"); err != nil {
+		t.Fatal(err)
+	}
+	j.GetPath("x").Set("a", 3)
+	if got := compact(j); got != `{"x":{"b":2,"a":3},"y":{"a":1}}` {
+		t.Errorf("MoveSubtree: got %s", got)
+	}
+
+	j = parseOrdered(t, `{"user":"u","password":"p","id":1}`)
+	if got := compact(j.Redact("password")); got != `{"user":"u","password":"***","id":1}` {
+		t.Errorf("Redact: got %s", got)
+	}
+}
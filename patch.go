@@ -0,0 +1,422 @@
+package gojson
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// mergePatch 按RFC 7396合并patch到target，返回合并后的值
+func mergePatch(target, patch interface{}) interface{} {
+	patchMap, ok := toMap(patch)
+	if !ok {
+		return patch
+	}
+
+	// target为OrderedDict时保留原有的key顺序，新建时沿用patch的类型
+	targetMap, ok := toMap(target)
+	if !ok {
+		if _, ordered := patch.(*OrderedDict); ordered {
+			target = NewOrderedDict()
+		} else {
+			target = make(map[string]interface{})
+		}
+		targetMap, _ = toMap(target)
+	}
+	for _, key := range orderedKeys(patch) {
+		val := patchMap[key]
+		if val == nil {
+			deleteMap(key, target)
+			continue
+		}
+		setMap(key, target, mergePatch(targetMap[key], val))
+	}
+	return target
+}
+
+// ApplyMergePatch 按RFC 7396 (JSON Merge Patch)将patch应用到当前对象并返回自身：
+// patch中的key覆盖原值，值为null时删除该key，patch不是k-v结构时直接替换整个对象。patch为nil时什么都不会发生
+func (j *GoJson) ApplyMergePatch(patch *GoJson) *GoJson {
+	if patch == nil {
+		return j
+	}
+	r := j.root()
+	r.Lock()
+	defer r.Unlock()
+	if err := r.checkFrozen("patch"); err != nil {
+		log.Println(err)
+		return j
+	}
+
+	j.data = mergePatch(j.data, patch.data)
+	maintainParent(j)
+	return j
+}
+
+// PatchOp RFC 6902 (JSON Patch)中的一个操作，Op为add、remove、replace、move、copy或test
+type PatchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value"`
+}
+
+// ParsePatch 解析RFC 6902格式的patch文档，如 [{"op":"add","path":"/a","value":1}]
+func ParsePatch(b []byte) ([]PatchOp, error) {
+	var ops []PatchOp
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	if err := decoder.Decode(&ops); err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+// parsePointer 将RFC 6901 (JSON Pointer)切分为各段，并还原 ~1 为 "/"、~0 为 "~"。空字符串表示整个文档
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("json pointer %s must start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+// pointerIndex 解析数组下标，不允许负数和前导0
+func pointerIndex(token string, length int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%s is not a valid index", token)
+	}
+	for _, c := range token {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("%s is not a valid index", token)
+		}
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index >= length {
+		return 0, fmt.Errorf("index %s out of range", token)
+	}
+	return index, nil
+}
+
+// getPointer 按JSON Pointer的各段取值
+func getPointer(node interface{}, tokens []string) (interface{}, error) {
+	for _, token := range tokens {
+		if m, ok := toMap(node); ok {
+			val, ok := m[token]
+			if !ok {
+				return nil, fmt.Errorf("key %s not found", token)
+			}
+			node = val
+			continue
+		}
+		if l, ok := toSlice(node); ok {
+			index, err := pointerIndex(token, len(l))
+			if err != nil {
+				return nil, err
+			}
+			node = l[index]
+			continue
+		}
+		return nil, fmt.Errorf("%v is not map or slice", node)
+	}
+	return node, nil
+}
+
+// Pointer 按RFC 6901 (JSON Pointer)获取值，如 /store/book/0/title，~1表示"/"，~0表示"~"。
+// 空字符串表示整个文档，无法解析时返回的GoJson对象 IsNil将为true。数组下标越界或无效时，返回的对象不与原对象关联
+func (j *GoJson) Pointer(p string) *GoJson {
+	tokens, err := parsePointer(p)
+	if err != nil {
+		return &GoJson{}
+	}
+
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	node := j
+	for _, token := range tokens {
+		if _, ok := toSlice(node.data); ok {
+			index, err := pointerIndex(token, node.len())
+			if err != nil {
+				return &GoJson{}
+			}
+			node = node.index(index)
+			continue
+		}
+		node = node.get(token)
+	}
+	return node
+}
+
+// modifyPointer 找到tokens最后一段所在的容器，交给op修改，并将修改后的容器逐层写回
+func modifyPointer(node interface{}, tokens []string, op func(container interface{}, last string) (interface{}, error)) (interface{}, error) {
+	if len(tokens) == 1 {
+		return op(node, tokens[0])
+	}
+
+	token := tokens[0]
+	switch v := node.(type) {
+	case map[string]interface{}, Dict, *OrderedDict:
+		m, _ := toMap(v)
+		child, ok := m[token]
+		if !ok {
+			return nil, fmt.Errorf("key %s not found", token)
+		}
+		newChild, err := modifyPointer(child, tokens[1:], op)
+		if err != nil {
+			return nil, err
+		}
+		m[token] = newChild
+		return v, nil
+	case []interface{}, List:
+		l, _ := toSlice(v)
+		index, err := pointerIndex(token, len(l))
+		if err != nil {
+			return nil, err
+		}
+		newChild, err := modifyPointer(l[index], tokens[1:], op)
+		if err != nil {
+			return nil, err
+		}
+		l[index] = newChild
+		return v, nil
+	default:
+		return nil, fmt.Errorf("%v is not map or slice", node)
+	}
+}
+
+func patchAdd(doc interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return modifyPointer(doc, tokens, func(container interface{}, last string) (interface{}, error) {
+		if setMap(last, container, value) {
+			return container, nil
+		}
+		if l, ok := toSlice(container); ok {
+			index := len(l)
+			if last != "-" {
+				var err error
+				if index, err = pointerIndex(last, len(l)+1); err != nil {
+					return nil, err
+				}
+			}
+			result, _ := insertSlice(index, container, value)
+			return result, nil
+		}
+		return nil, fmt.Errorf("%v is not map or slice", container)
+	})
+}
+
+func patchRemove(doc interface{}, tokens []string) (interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, errors.New("cannot remove the whole document")
+	}
+	return modifyPointer(doc, tokens, func(container interface{}, last string) (interface{}, error) {
+		if m, ok := toMap(container); ok {
+			if _, ok := m[last]; !ok {
+				return nil, fmt.Errorf("key %s not found", last)
+			}
+			deleteMap(last, container)
+			return container, nil
+		}
+		if l, ok := toSlice(container); ok {
+			index, err := pointerIndex(last, len(l))
+			if err != nil {
+				return nil, err
+			}
+			result, _ := removeSlice(index, container)
+			return result, nil
+		}
+		return nil, fmt.Errorf("%v is not map or slice", container)
+	})
+}
+
+func patchReplace(doc interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return modifyPointer(doc, tokens, func(container interface{}, last string) (interface{}, error) {
+		if m, ok := toMap(container); ok {
+			if _, ok := m[last]; !ok {
+				return nil, fmt.Errorf("key %s not found", last)
+			}
+			m[last] = value
+			return container, nil
+		}
+		if l, ok := toSlice(container); ok {
+			index, err := pointerIndex(last, len(l))
+			if err != nil {
+				return nil, err
+			}
+			l[index] = value
+			return container, nil
+		}
+		return nil, fmt.Errorf("%v is not map or slice", container)
+	})
+}
+
+func applyPatchOp(doc interface{}, op PatchOp) (interface{}, error) {
+	tokens, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+	value := op.Value
+	if v, ok := value.(*GoJson); ok {
+		value = v.data
+	}
+
+	switch op.Op {
+	case "add":
+		return patchAdd(doc, tokens, deepCopy(value))
+	case "remove":
+		return patchRemove(doc, tokens)
+	case "replace":
+		return patchReplace(doc, tokens, deepCopy(value))
+	case "move", "copy":
+		fromTokens, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := getPointer(doc, fromTokens)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "copy" {
+			return patchAdd(doc, tokens, deepCopy(value))
+		}
+		if op.From == op.Path {
+			return doc, nil
+		}
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, fmt.Errorf("cannot move %s into its own child %s", op.From, op.Path)
+		}
+		if doc, err = patchRemove(doc, fromTokens); err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, tokens, value)
+	case "test":
+		actual, err := getPointer(doc, tokens)
+		if err != nil {
+			return nil, err
+		}
+		if !equalValue(actual, value) {
+			return nil, fmt.Errorf("test failed, %v is not equal to %v", actual, value)
+		}
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("unsupported op %s", op.Op)
+	}
+}
+
+// ApplyPatch 按RFC 6902 (JSON Patch)依次执行ops，path和from为JSON Pointer格式，如 /a/0/b。
+// 任意一个操作失败时返回error，当前对象保持不变。
+// 操作在数据的副本上执行，成功后替换当前数据，之前通过Get、Index得到的子节点不会再与之关联
+func (j *GoJson) ApplyPatch(ops []PatchOp) error {
+	r := j.root()
+	r.Lock()
+	defer r.Unlock()
+	if err := r.checkFrozen("patch"); err != nil {
+		return err
+	}
+
+	doc := deepCopy(j.data)
+	for i, op := range ops {
+		var err error
+		if doc, err = applyPatchOp(doc, op); err != nil {
+			return fmt.Errorf("patch op %d (%s %s) failed: %v", i, op.Op, op.Path, err)
+		}
+	}
+
+	j.data = doc
+	maintainParent(j)
+	return nil
+}
+
+// escapePointer 按RFC 6901转义JSON Pointer中的一段，"~"转义为~0，"/"转义为~1
+func escapePointer(token string) string {
+	return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+}
+
+// diffPatch 比较a和b，将把a变为b所需的操作追加到ops
+func diffPatch(pointer string, a, b interface{}, ops []PatchOp) []PatchOp {
+	aMap, aIsMap := toMap(a)
+	bMap, bIsMap := toMap(b)
+	if aIsMap && bIsMap {
+		keys := make([]string, 0, len(aMap)+len(bMap))
+		for key := range aMap {
+			keys = append(keys, key)
+		}
+		for key := range bMap {
+			if _, ok := aMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			childPointer := pointer + "/" + escapePointer(key)
+			aVal, aOk := aMap[key]
+			bVal, bOk := bMap[key]
+			switch {
+			case !bOk:
+				ops = append(ops, PatchOp{Op: "remove", Path: childPointer})
+			case !aOk:
+				ops = append(ops, PatchOp{Op: "add", Path: childPointer, Value: deepCopy(bVal)})
+			default:
+				ops = diffPatch(childPointer, aVal, bVal, ops)
+			}
+		}
+		return ops
+	}
+
+	aSlice, aIsSlice := toSlice(a)
+	bSlice, bIsSlice := toSlice(b)
+	if aIsSlice && bIsSlice {
+		common := len(aSlice)
+		if len(bSlice) < common {
+			common = len(bSlice)
+		}
+		for i := 0; i < common; i++ {
+			ops = diffPatch(pointer+"/"+strconv.Itoa(i), aSlice[i], bSlice[i], ops)
+		}
+		// 从后往前删除，保证前面元素的下标不变
+		for i := len(aSlice) - 1; i >= common; i-- {
+			ops = append(ops, PatchOp{Op: "remove", Path: pointer + "/" + strconv.Itoa(i)})
+		}
+		for i := common; i < len(bSlice); i++ {
+			ops = append(ops, PatchOp{Op: "add", Path: pointer + "/" + strconv.Itoa(i), Value: deepCopy(bSlice[i])})
+		}
+		return ops
+	}
+
+	if !equalValue(a, b) {
+		ops = append(ops, PatchOp{Op: "replace", Path: pointer, Value: deepCopy(b)})
+	}
+	return ops
+}
+
+// DiffPatch 比较当前对象与other，返回把当前对象变为other的RFC 6902 (JSON Patch)操作，
+// 对当前对象的副本执行ApplyPatch即可得到other。数组按下标逐个比较，数字比较规则与Equals相同
+func (j *GoJson) DiffPatch(other *GoJson) ([]PatchOp, error) {
+	if other == nil {
+		return nil, errors.New("other is nil")
+	}
+
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	return diffPatch("", j.data, other.data, []PatchOp{}), nil
+}
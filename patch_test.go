@@ -0,0 +1,124 @@
+package gojson
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyPatch(t *testing.T) {
+	j := NewJsonFromString(`{"foo":["bar","baz"],"a/b":1,"m~n":2,"obj":{"x":1}}`)
+	ops, err := ParsePatch([]byte(`[
+		{"op":"add","path":"/foo/1","value":"qux"},
+		{"op":"add","path":"/foo/-","value":"end"},
+		{"op":"remove","path":"/foo/0"},
+		{"op":"replace","path":"/a~1b","value":10},
+		{"op":"test","path":"/m~0n","value":2.0},
+		{"op":"copy","from":"/obj","path":"/copied"},
+		{"op":"move","from":"/m~0n","path":"/obj/y"},
+		{"op":"add","path":"/obj/z~1w","value":{"k":[1]}}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := j.ApplyPatch(ops); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"a/b":10,"copied":{"x":1},"foo":["qux","baz","end"],"obj":{"x":1,"y":2,"z/w":{"k":[1]}}}`
+	if got := compact(j); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+
+	// copy得到的是副本，修改原值不影响它
+	j.GetPath("obj").Set("x", 5)
+	if got := compact(j.Get("copied")); got != `{"x":1}` {
+		t.Errorf("copied = %s", got)
+	}
+
+	if n, err := j.Pointer("/obj/z~1w/k/0").Int(); err != nil || n != 1 {
+		t.Errorf("Pointer with ~1 = %d, %v", n, err)
+	}
+}
+
+func TestApplyPatchWholeDocument(t *testing.T) {
+	j := NewJsonFromString(`{"a":1}`)
+	if err := j.ApplyPatch([]PatchOp{{Op: "replace", Path: "", Value: []interface{}{1}}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := compact(j); got != `[1]` {
+		t.Errorf("got %s", got)
+	}
+	if err := j.ApplyPatch([]PatchOp{{Op: "remove", Path: ""}}); err == nil {
+		t.Error("removing the whole document should fail")
+	}
+}
+
+func TestApplyPatchFailureKeepsDocument(t *testing.T) {
+	src := `{"a":{"b":[1,2]},"c":"x"}`
+	cases := []struct {
+		op   PatchOp
+		want string
+	}{
+		{PatchOp{Op: "test", Path: "/c", Value: "y"}, "test failed"},
+		{PatchOp{Op: "test", Path: "/missing", Value: 1}, "not found"},
+		{PatchOp{Op: "remove", Path: "/a/b/2"}, "out of range"},
+		{PatchOp{Op: "replace", Path: "/a/b/01", Value: 1}, "not a valid index"},
+		{PatchOp{Op: "add", Path: "/a/b/3", Value: 1}, "out of range"},
+		{PatchOp{Op: "move", From: "/a", Path: "/a/d"}, "own child"},
+		{PatchOp{Op: "copy", From: "/nope", Path: "/d"}, "not found"},
+		{PatchOp{Op: "add", Path: "a", Value: 1}, "must start with /"},
+		{PatchOp{Op: "inc", Path: "/c"}, "unsupported op"},
+	}
+	for _, c := range cases {
+		j := NewJsonFromString(src)
+		// 前面成功的操作在失败时同样不生效
+		err := j.ApplyPatch([]PatchOp{{Op: "add", Path: "/added", Value: true}, c.op})
+		if err == nil || !strings.Contains(err.Error(), c.want) || !strings.Contains(err.Error(), "patch op 1") {
+			t.Errorf("%s %s: error = %v, want %q", c.op.Op, c.op.Path, err, c.want)
+		}
+		if got := compact(j); got != src {
+			t.Errorf("%s %s: document changed to %s", c.op.Op, c.op.Path, got)
+		}
+	}
+}
+
+func TestDiffPatchRoundTrip(t *testing.T) {
+	pairs := [][2]string{
+		{`{"a":1,"b":{"c":[1,2,3]},"d":"x"}`, `{"a":1.0,"b":{"c":[1,5]},"e":null}`},
+		{`{"l":[1]}`, `{"l":[1,{"n":[2]},3]}`},
+		{`{"a/b":{"m~n":1}}`, `{"a/b":{"m~n":2,"~":[]}}`},
+		{`[1,2]`, `{"k":true}`},
+		{`{"same":[1,{"x":null}]}`, `{"same":[1,{"x":null}]}`},
+	}
+	for _, pair := range pairs {
+		a, b := NewJsonFromString(pair[0]), NewJsonFromString(pair[1])
+		ops, err := a.DiffPatch(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		patched := a.Clone()
+		if err := patched.ApplyPatch(ops); err != nil {
+			t.Fatalf("%s -> %s: %v", pair[0], pair[1], err)
+		}
+		if !patched.Equals(b) {
+			t.Errorf("%s -> %s: patched to %s", pair[0], pair[1], compact(patched))
+		}
+		if got := compact(a); got != pair[0] {
+			t.Errorf("DiffPatch changed the source to %s", got)
+		}
+	}
+
+	// 数值相同的数字不产生操作
+	ops, _ := NewJsonFromString(`{"n":1,"l":[1.50]}`).DiffPatch(NewJsonFromString(`{"n":1.0,"l":[1.5]}`))
+	if len(ops) != 0 {
+		t.Errorf("ops = %+v", ops)
+	}
+
+	// 生成的patch中修改的值是副本，修改other不影响已生成的操作
+	b := NewJsonFromString(`{"o":{"k":1}}`)
+	ops, _ = NewJsonFromString(`{}`).DiffPatch(b)
+	b.Get("o").Set("k", 2)
+	target := NewJsonFromString(`{}`)
+	if err := target.ApplyPatch(ops); err != nil || compact(target) != `{"o":{"k":1}}` {
+		t.Errorf("got %s, %v", compact(target), err)
+	}
+}
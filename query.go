@@ -0,0 +1,218 @@
+package gojson
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	stepKey = iota
+	stepWildcard
+	stepIndex
+	stepSlice
+)
+
+// queryStep JSONPath表达式中的一段
+type queryStep struct {
+	kind      int
+	recursive bool // 前面是否为 ".."
+	key       string
+	index     int
+	start     *int
+	end       *int
+}
+
+// parseQuery 解析JSONPath表达式，支持 $、.key、['key']、[n]、[*]、.*、..、[start:end]
+func parseQuery(expr string) ([]queryStep, error) {
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("jsonpath %s must start with $", expr)
+	}
+
+	var steps []queryStep
+	pos := 1
+	for pos < len(expr) {
+		recursive := false
+		switch {
+		case strings.HasPrefix(expr[pos:], ".."):
+			recursive = true
+			pos += 2
+		case expr[pos] == '.':
+			pos++
+		case expr[pos] == '[':
+		default:
+			return nil, fmt.Errorf("jsonpath %s: unsupported syntax at %d", expr, pos)
+		}
+		if pos >= len(expr) {
+			return nil, fmt.Errorf("jsonpath %s: unexpected end", expr)
+		}
+
+		if expr[pos] == '[' {
+			end := strings.IndexByte(expr[pos:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("jsonpath %s: missing ] at %d", expr, pos)
+			}
+			step, err := parseBracket(expr[pos+1 : pos+end])
+			if err != nil {
+				return nil, fmt.Errorf("jsonpath %s: %v", expr, err)
+			}
+			step.recursive = recursive
+			steps = append(steps, step)
+			pos += end + 1
+			continue
+		}
+
+		end := pos
+		for end < len(expr) && expr[end] != '.' && expr[end] != '[' {
+			end++
+		}
+		name := expr[pos:end]
+		if name == "" {
+			return nil, fmt.Errorf("jsonpath %s: empty name at %d", expr, pos)
+		}
+		if name == "*" {
+			steps = append(steps, queryStep{kind: stepWildcard, recursive: recursive})
+		} else {
+			steps = append(steps, queryStep{kind: stepKey, recursive: recursive, key: name})
+		}
+		pos = end
+	}
+	return steps, nil
+}
+
+func parseBracket(content string) (queryStep, error) {
+	content = strings.TrimSpace(content)
+	switch {
+	case content == "*":
+		return queryStep{kind: stepWildcard}, nil
+	case len(content) >= 2 && (content[0] == '\'' || content[0] == '"') && content[len(content)-1] == content[0]:
+		return queryStep{kind: stepKey, key: content[1 : len(content)-1]}, nil
+	case strings.Contains(content, ":"):
+		parts := strings.Split(content, ":")
+		if len(parts) != 2 {
+			return queryStep{}, fmt.Errorf("unsupported slice [%s]", content)
+		}
+		step := queryStep{kind: stepSlice}
+		for i, part := range parts {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return queryStep{}, fmt.Errorf("unsupported slice [%s]", content)
+			}
+			if i == 0 {
+				step.start = &n
+			} else {
+				step.end = &n
+			}
+		}
+		return step, nil
+	default:
+		n, err := strconv.Atoi(content)
+		if err != nil {
+			return queryStep{}, fmt.Errorf("unsupported selector [%s]", content)
+		}
+		return queryStep{kind: stepIndex, index: n}, nil
+	}
+}
+
+// descendants 返回node及其所有子孙节点，深度优先，map按key的字典序
+func descendants(node *GoJson) []*GoJson {
+	result := []*GoJson{node}
+	for _, child := range children(node) {
+		result = append(result, descendants(child)...)
+	}
+	return result
+}
+
+// children 返回node的所有直接子节点，map按key的字典序
+func children(node *GoJson) []*GoJson {
+	var result []*GoJson
+	if m, ok := toMap(node.data); ok {
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			result = append(result, &GoJson{prev: node, prevKey: key, data: m[key], exists: true})
+		}
+	}
+	if l, ok := toSlice(node.data); ok {
+		for i, val := range l {
+			result = append(result, &GoJson{prev: node, prevIndex: i, data: val, exists: true})
+		}
+	}
+	return result
+}
+
+func applyStep(node *GoJson, step queryStep) []*GoJson {
+	switch step.kind {
+	case stepKey:
+		if m, ok := toMap(node.data); ok {
+			if val, ok := m[step.key]; ok {
+				return []*GoJson{{prev: node, prevKey: step.key, data: val, exists: true}}
+			}
+		}
+	case stepWildcard:
+		return children(node)
+	case stepIndex:
+		if l, ok := toSlice(node.data); ok {
+			index := step.index
+			if index < 0 {
+				index += len(l)
+			}
+			if index >= 0 && index < len(l) {
+				return []*GoJson{{prev: node, prevIndex: index, data: l[index], exists: true}}
+			}
+		}
+	case stepSlice:
+		if l, ok := toSlice(node.data); ok {
+			start, end := 0, len(l)
+			if step.start != nil {
+				start = clampIndex(*step.start, len(l))
+			}
+			if step.end != nil {
+				end = clampIndex(*step.end, len(l))
+			}
+			var result []*GoJson
+			for i := start; i < end; i++ {
+				result = append(result, &GoJson{prev: node, prevIndex: i, data: l[i], exists: true})
+			}
+			return result
+		}
+	}
+	return nil
+}
+
+// Query 按JSONPath表达式查询，返回所有匹配的节点，如 $.store.book[*].author、$..price、$.list[0:2]。
+// 支持 .key、['key']、[n]、[*]、.*、.. 和 [start:end]，不支持的语法返回error
+func (j *GoJson) Query(expr string) ([]*GoJson, error) {
+	steps, err := parseQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	nodes := []*GoJson{j}
+	for _, step := range steps {
+		var next []*GoJson
+		for _, node := range nodes {
+			if step.recursive {
+				for _, d := range descendants(node) {
+					next = append(next, applyStep(d, step)...)
+				}
+				continue
+			}
+			next = append(next, applyStep(node, step)...)
+		}
+		nodes = next
+	}
+	return nodes, nil
+}
@@ -0,0 +1,114 @@
+package gojson
+
+import (
+	"strings"
+	"testing"
+)
+
+const storeJson = `{"store":{"book":[` +
+	`{"author":"A","title":"T1","price":8},` +
+	`{"author":"B","title":"T2","price":12},` +
+	`{"author":"C","title":"T3","price":9}],` +
+	`"bicycle":{"color":"red","price":20}},"list":[1,2,3]}`
+
+// queryValues 返回Query结果中每个节点的String，以","连接
+func queryValues(t *testing.T, j *GoJson, expr string) string {
+	t.Helper()
+	nodes, err := j.Query(expr)
+	if err != nil {
+		t.Fatalf("%s: %v", expr, err)
+	}
+	values := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		values = append(values, compact(node))
+	}
+	return strings.Join(values, ",")
+}
+
+func TestQuery(t *testing.T) {
+	j := NewJsonFromString(storeJson)
+
+	if got := queryValues(t, j, `$.store.book[*].author`); got != `A,B,C` {
+		t.Errorf("wildcard: got %s", got)
+	}
+	if got := queryValues(t, j, `$['store']["bicycle"].color`); got != `red` {
+		t.Errorf("quoted keys: got %s", got)
+	}
+	if got := queryValues(t, j, `$.store.*`); !strings.HasPrefix(got, `{"color":"red"`) {
+		t.Errorf("child wildcard should list bicycle first: got %s", got)
+	}
+	if got := queryValues(t, j, `$.list[-1]`); got != `3` {
+		t.Errorf("negative index: got %s", got)
+	}
+	if got := queryValues(t, j, `$.list[3]`); got != `` {
+		t.Errorf("index out of range: got %s", got)
+	}
+}
+
+func TestQuerySlice(t *testing.T) {
+	j := NewJsonFromString(storeJson)
+	for expr, want := range map[string]string{
+		`$.store.book[0:2].title`:   `T1,T2`,
+		`$.store.book[:1].title`:    `T1`,
+		`$.store.book[-2:].title`:   `T2,T3`,
+		`$.store.book[1:100].title`: `T2,T3`,
+		`$.list[2:1]`:               ``,
+		`$.list[:]`:                 `1,2,3`,
+	} {
+		if got := queryValues(t, j, expr); got != want {
+			t.Errorf("%s: got %s, want %s", expr, got, want)
+		}
+	}
+}
+
+func TestQueryRecursiveDescent(t *testing.T) {
+	j := NewJsonFromString(storeJson)
+
+	// 深度优先，map按key的字典序：bicycle在book之前
+	if got := queryValues(t, j, `$..price`); got != `20,8,12,9` {
+		t.Errorf("$..price: got %s", got)
+	}
+	if got := queryValues(t, j, `$..book[1].author`); got != `B` {
+		t.Errorf("$..book[1].author: got %s", got)
+	}
+	if got := queryValues(t, j, `$.store..color`); got != `red` {
+		t.Errorf("$.store..color: got %s", got)
+	}
+	if got := queryValues(t, j, `$..missing`); got != `` {
+		t.Errorf("$..missing: got %s", got)
+	}
+}
+
+func TestQueryResultsWriteBack(t *testing.T) {
+	j := NewJsonFromString(`{"items":[{"n":1},{"n":2}]}`)
+	nodes, err := j.Query(`$.items[*].n`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, node := range nodes {
+		if node.Path() == "items.1.n" {
+			node.SetPath("", 20)
+		}
+	}
+	if got := compact(j); got != `{"items":[{"n":1},{"n":20}]}` {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestQueryInvalid(t *testing.T) {
+	j := NewJsonFromString(storeJson)
+	for _, expr := range []string{
+		`store.book`,
+		`$.`,
+		`$..`,
+		`$.store[`,
+		`$.list[x]`,
+		`$.list[1:2:3]`,
+		`$.list[a:]`,
+		`$store`,
+	} {
+		if nodes, err := j.Query(expr); err == nil {
+			t.Errorf("%s: expected error, got %d nodes", expr, len(nodes))
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package gojson
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// schemaPath 用于错误信息的路径，根节点显示为$
+func schemaPath(path string) string {
+	if path == "" {
+		return "$"
+	}
+	return path
+}
+
+// matchType 判断val是否符合JSON Schema的type，支持object、array、string、number、integer、boolean、null
+func matchType(val interface{}, typ string) bool {
+	kind := kindOf(val)
+	switch typ {
+	case "object":
+		return kind == KindObject
+	case "array":
+		return kind == KindArray
+	case "string":
+		return kind == KindString
+	case "number":
+		return kind == KindNumber
+	case "integer":
+		rat, ok := toRat(val)
+		return ok && rat.IsInt()
+	case "boolean":
+		return kind == KindBool
+	case "null":
+		return kind == KindNull
+	default:
+		return false
+	}
+}
+
+// validateSchema 按schema校验val，将所有错误追加到errs
+func validateSchema(path string, val interface{}, schema map[string]interface{}, errs []error) []error {
+	if typ, ok := schema["type"]; ok {
+		var types []string
+		if s, ok := typ.(string); ok {
+			types = []string{s}
+		} else if l, ok := toSlice(typ); ok {
+			for _, item := range l {
+				types = append(types, ToString(item))
+			}
+		}
+		matched := false
+		for _, t := range types {
+			if matchType(val, t) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return append(errs, fmt.Errorf("%s: %v is not %v", schemaPath(path), val, typ))
+		}
+	}
+
+	if enum, ok := toSlice(schema["enum"]); ok {
+		found := false
+		for _, item := range enum {
+			if equalValue(val, item) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, fmt.Errorf("%s: %v is not one of %v", schemaPath(path), val, enum))
+		}
+	}
+
+	if kindOf(val) == KindNumber {
+		if c, ok := compareNumbers(val, schema["minimum"]); ok && c < 0 {
+			errs = append(errs, fmt.Errorf("%s: %v is less than minimum %v", schemaPath(path), val, schema["minimum"]))
+		}
+		if c, ok := compareNumbers(val, schema["maximum"]); ok && c > 0 {
+			errs = append(errs, fmt.Errorf("%s: %v is greater than maximum %v", schemaPath(path), val, schema["maximum"]))
+		}
+	}
+
+	if s, ok := val.(string); ok {
+		length := utf8.RuneCountInString(s)
+		if minLen, err := ToInt(schema["minLength"]); err == nil && length < minLen {
+			errs = append(errs, fmt.Errorf("%s: length %d is less than minLength %d", schemaPath(path), length, minLen))
+		}
+		if maxLen, err := ToInt(schema["maxLength"]); err == nil && length > maxLen {
+			errs = append(errs, fmt.Errorf("%s: length %d is greater than maxLength %d", schemaPath(path), length, maxLen))
+		}
+	}
+
+	if m, ok := toMap(val); ok {
+		if required, ok := toSlice(schema["required"]); ok {
+			for _, item := range required {
+				key := ToString(item)
+				if _, ok := m[key]; !ok {
+					errs = append(errs, fmt.Errorf("%s: required key %s not found", schemaPath(path), key))
+				}
+			}
+		}
+		if properties, ok := toMap(schema["properties"]); ok {
+			keys := make([]string, 0, len(properties))
+			for key := range properties {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				child, ok := m[key]
+				if !ok {
+					continue
+				}
+				if childSchema, ok := toMap(properties[key]); ok {
+					errs = validateSchema(joinPath(path, escapeKey(key, ".")), child, childSchema, errs)
+				}
+			}
+		}
+	}
+
+	if l, ok := toSlice(val); ok {
+		if items, ok := toMap(schema["items"]); ok {
+			for i, item := range l {
+				errs = validateSchema(joinPath(path, strconv.Itoa(i)), item, items, errs)
+			}
+		}
+	}
+	return errs
+}
+
+// ValidateSchema 按JSON Schema的一个子集校验当前对象，返回所有不符合的地方，全部符合时返回nil。
+// 支持type、required、properties、items、minimum、maximum、minLength、maxLength和enum，
+// 错误信息中的路径为GetPath格式，根节点为$
+func (j *GoJson) ValidateSchema(schema *GoJson) []error {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	var schemaMap map[string]interface{}
+	if schema != nil {
+		schemaMap, _ = toMap(schema.data)
+	}
+	if schemaMap == nil {
+		return []error{errors.New("schema is not map")}
+	}
+	return validateSchema("", j.data, schemaMap, nil)
+}
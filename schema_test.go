@@ -0,0 +1,57 @@
+package gojson
+
+import (
+	"strings"
+	"testing"
+)
+
+const userSchema = `{
+	"type": "object",
+	"required": ["name", "age"],
+	"properties": {
+		"name": {"type": "string", "minLength": 2, "maxLength": 8},
+		"age": {"type": "integer", "minimum": 0, "maximum": 150},
+		"role": {"enum": ["admin", "user"]},
+		"tags": {"type": "array", "items": {"type": ["string", "null"]}},
+		"a.b": {"type": "boolean"}
+	}
+}`
+
+func TestValidateSchema(t *testing.T) {
+	schema := NewJsonFromString(userSchema)
+	valid := NewJsonFromString(`{"name":"张三","age":30.0,"role":"user","tags":["x",null],"a.b":true,"extra":1}`)
+	if errs := valid.ValidateSchema(schema); errs != nil {
+		t.Errorf("unexpected errors %v", errs)
+	}
+
+	invalid := NewJsonFromString(`{"name":"abcdefghi","age":200,"role":"root","tags":["x",1],"a.b":"yes"}`)
+	var got []string
+	for _, err := range invalid.ValidateSchema(schema) {
+		got = append(got, err.Error())
+	}
+	want := []string{
+		`a\.b: yes is not boolean`,
+		`age: 200 is greater than maximum 150`,
+		`name: length 9 is greater than maxLength 8`,
+		`role: root is not one of [admin user]`,
+		`tags.1: 1 is not [string null]`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestValidateSchemaTypeMismatchStops(t *testing.T) {
+	schema := NewJsonFromString(userSchema)
+
+	// 类型不符时不再检查该节点的其他约束
+	errs := NewJsonFromString(`[1]`).ValidateSchema(schema)
+	if len(errs) != 1 || errs[0].Error() != "$: [1] is not object" {
+		t.Errorf("got %v", errs)
+	}
+
+	errs = NewJsonFromString(`{"age":1.5}`).ValidateSchema(schema)
+	if len(errs) != 2 || !strings.Contains(errs[0].Error(), "required key name") || !strings.Contains(errs[1].Error(), "1.5 is not integer") {
+		t.Errorf("got %v", errs)
+	}
+}
@@ -0,0 +1,73 @@
+package gojson
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// Scan 实现sql.Scanner，可以直接作为database/sql的扫描目标，如 rows.Scan(&j)。
+// src为[]byte或string时解析为json并替换当前数据，src为nil(数据库中的NULL)时数据置为nil，解析失败时当前数据保持不变
+func (j *GoJson) Scan(src interface{}) error {
+	var data interface{}
+	switch v := src.(type) {
+	case nil:
+	case []byte:
+		js, err := decodeJsonAll(bytes.NewReader(v), true)
+		if err != nil {
+			return err
+		}
+		data = js.data
+	case string:
+		js, err := decodeJsonAll(strings.NewReader(v), true)
+		if err != nil {
+			return err
+		}
+		data = js.data
+	default:
+		return fmt.Errorf("%T cannot scan into GoJson", src)
+	}
+
+	r := j.root()
+	r.Lock()
+	defer r.Unlock()
+	if err := r.checkFrozen("scan"); err != nil {
+		return err
+	}
+
+	j.data = data
+	maintainParent(j)
+	return nil
+}
+
+// sqlValuer 将GoJson包装为driver.Valuer
+type sqlValuer struct {
+	j *GoJson
+}
+
+// Value 实现driver.Valuer
+func (v sqlValuer) Value() (driver.Value, error) {
+	return v.j.DriverValue()
+}
+
+// DriverValue 返回写入数据库的值：json编码后的bytes，数据为nil时返回nil(数据库中的NULL)
+func (j *GoJson) DriverValue() (driver.Value, error) {
+	if j == nil {
+		return nil, nil
+	}
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	if j.data == nil {
+		return nil, nil
+	}
+	return marshal(j.data)
+}
+
+// Valuer 返回实现了driver.Valuer的对象，可以直接作为database/sql的查询参数，如 db.Exec(query, j.Valuer())。
+// GoJson的Value方法已用于返回原始数据，因此无法直接实现driver.Valuer
+func (j *GoJson) Valuer() driver.Valuer {
+	return sqlValuer{j: j}
+}
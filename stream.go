@@ -0,0 +1,151 @@
+package gojson
+
+import (
+	"bufio"
+	"bytes"
+	sysjson "encoding/json"
+	"fmt"
+	"io"
+	"unicode"
+)
+
+// StreamDecoder 流式解析器，每次只解析一个元素，内存占用与单个元素大小相关
+type StreamDecoder struct {
+	reader  *bufio.Reader
+	decoder *sysjson.Decoder
+	started bool
+	inArray bool
+	done    bool
+	err     error
+}
+
+// NewStreamDecoder 从r创建流式解析器。输入的顶层为数组时，Next逐个返回数组中的元素，数组之后还有数据时解析出错；
+// 否则按NDJSON(每行一个json)处理，Next逐个返回每个顶层值。每行都是数组的NDJSON应使用NewNDJSONDecoder
+func NewStreamDecoder(r io.Reader) *StreamDecoder {
+	reader := bufio.NewReader(r)
+	decoder := sysjson.NewDecoder(reader)
+	decoder.UseNumber()
+	return &StreamDecoder{reader: reader, decoder: decoder}
+}
+
+// NewNDJSONDecoder 从r创建按NDJSON处理的流式解析器，Next逐个返回每个顶层值，顶层为数组时也作为一个整体返回
+func NewNDJSONDecoder(r io.Reader) *StreamDecoder {
+	s := NewStreamDecoder(r)
+	s.started = true
+	return s
+}
+
+// start 跳过开头的空白字符，判断顶层是否为数组
+func (s *StreamDecoder) start() error {
+	s.started = true
+	for {
+		r, _, err := s.reader.ReadRune()
+		if err == io.EOF {
+			s.done = true
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if unicode.IsSpace(r) {
+			continue
+		}
+		if err := s.reader.UnreadRune(); err != nil {
+			return err
+		}
+		if r == '[' {
+			if _, err := s.decoder.Token(); err != nil {
+				return err
+			}
+			s.inArray = true
+		}
+		return nil
+	}
+}
+
+// Next 返回下一个元素，没有更多元素或解析出错时返回false，出错原因通过Err获取
+func (s *StreamDecoder) Next() (*GoJson, bool) {
+	if s.done || s.err != nil {
+		return nil, false
+	}
+	if !s.started {
+		if err := s.start(); err != nil {
+			s.err = err
+			return nil, false
+		}
+		if s.done {
+			return nil, false
+		}
+	}
+
+	if s.inArray && !s.decoder.More() {
+		if _, err := s.decoder.Token(); err != nil {
+			s.err = err
+		} else if _, err := s.decoder.Token(); err != io.EOF {
+			// 顶层数组之后还有数据，如每行都是数组的NDJSON，不能当作同一个数组的元素继续返回
+			s.err = fmt.Errorf("unexpected data after top-level array at offset %d", s.decoder.InputOffset())
+		}
+		s.done = true
+		return nil, false
+	}
+
+	var f interface{}
+	if err := s.decoder.Decode(&f); err != nil {
+		if err == io.EOF && !s.inArray {
+			s.done = true
+		} else {
+			s.err = err
+		}
+		return nil, false
+	}
+	return &GoJson{data: f}, true
+}
+
+// Err 返回解析过程中遇到的错误，正常结束时返回nil
+func (s *StreamDecoder) Err() error {
+	return s.err
+}
+
+// NewJsonSliceFromNDJSON 按NDJSON(每行一个json)格式读取r中的所有行，跳过空行。
+// 任意一行解析失败时返回error，error中包含行号
+func NewJsonSliceFromNDJSON(r io.Reader) ([]*GoJson, error) {
+	reader := bufio.NewReader(r)
+	var result []*GoJson
+	for lineNo := 1; ; lineNo++ {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			js, parseErr := NewJsonFromBytesE(trimmed)
+			if parseErr != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNo, parseErr)
+			}
+			result = append(result, js)
+		}
+		if err == io.EOF {
+			return result, nil
+		}
+	}
+}
+
+// WriteNDJSON 将数组中的每个元素编码为一行json写入w，即NDJSON格式。当json不为slice时返回error
+func (j *GoJson) WriteNDJSON(w io.Writer) error {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	l, ok := toSlice(j.data)
+	if !ok {
+		return fmt.Errorf("%v is not slice", j.data)
+	}
+	writer := bufio.NewWriter(w)
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(EscapeHTML)
+	for _, item := range l {
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,56 @@
+package gojson
+
+import (
+	"strings"
+	"testing"
+)
+
+// drain 读取s中的所有元素，返回每个元素的json和最后的error
+func drain(s *StreamDecoder) ([]string, error) {
+	var result []string
+	for {
+		item, ok := s.Next()
+		if !ok {
+			return result, s.Err()
+		}
+		result = append(result, compact(item))
+	}
+}
+
+func TestStreamDecoder(t *testing.T) {
+	cases := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"array", ` [1, {"a":"b"}, [2]] `, `1 {"a":"b"} [2]`, false},
+		{"empty array", `[]`, ``, false},
+		{"ndjson", "{\"a\":1}\n\n{\"a\":2}\n3\n", `{"a":1} {"a":2} 3`, false},
+		{"empty", "  \n", ``, false},
+		{"ndjson of arrays", "[1,2]\n[3,4]\n", `1 2`, true},
+		{"trailing value", `[1] 2`, `1`, true},
+		{"trailing bracket", `[1]]`, `1`, true},
+		{"unterminated array", `[1,`, `1`, true},
+		{"invalid ndjson line", "{\"a\":1}\n{x}\n", `{"a":1}`, true},
+	}
+	for _, c := range cases {
+		got, err := drain(NewStreamDecoder(strings.NewReader(c.input)))
+		if strings.Join(got, " ") != c.want {
+			t.Errorf("%s: got %v, want %s", c.name, got, c.want)
+		}
+		if (err != nil) != c.wantErr {
+			t.Errorf("%s: err = %v", c.name, err)
+		}
+	}
+}
+
+func TestNDJSONDecoderArrays(t *testing.T) {
+	got, err := drain(NewNDJSONDecoder(strings.NewReader("[1,2]\n[3,4]\n{\"a\":[5]}")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, " ") != `[1,2] [3,4] {"a":[5]}` {
+		t.Errorf("got %v", got)
+	}
+}
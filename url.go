@@ -0,0 +1,69 @@
+package gojson
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// addQueryValues 将val展开写入values，k-v结构的key用"."连接，标量数组展开为重复的key
+func addQueryValues(values url.Values, prefix string, val interface{}) {
+	if m, ok := toMap(val); ok {
+		for key, child := range m {
+			addQueryValues(values, joinPath(prefix, escapeKey(key, ".")), child)
+		}
+		return
+	}
+	if l, ok := toSlice(val); ok {
+		for i, child := range l {
+			if _, ok := toMap(child); ok {
+				addQueryValues(values, joinPath(prefix, strconv.Itoa(i)), child)
+				continue
+			}
+			if _, ok := toSlice(child); ok {
+				addQueryValues(values, joinPath(prefix, strconv.Itoa(i)), child)
+				continue
+			}
+			addQueryValues(values, prefix, child)
+		}
+		return
+	}
+	if val == nil {
+		values.Add(prefix, "")
+		return
+	}
+	values.Add(prefix, ToString(val))
+}
+
+// ToQueryValues 将k-v结构转换为url.Values：标量通过ToString转换为字符串，null为空字符串；
+// 标量数组展开为重复的key，如 {"a":[1,2]} 转换为 a=1&a=2；嵌套的k-v结构使用GetPath格式的key，如 a.b=1。
+// 源数据不是k-v结构时返回空的url.Values
+func (j *GoJson) ToQueryValues() url.Values {
+	r := j.root()
+	r.RLock()
+	defer r.RUnlock()
+
+	values := url.Values{}
+	if _, ok := toMap(j.data); ok {
+		addQueryValues(values, "", j.data)
+	}
+	return values
+}
+
+// NewJsonFromQuery 从url.Values创建GoJson对象，只出现一次的key对应字符串，重复的key对应字符串数组。
+// key按GetPath格式展开为嵌套结构，如 a.b=1 得到 {"a":{"b":"1"}}，路径冲突的key会被忽略并打印日志。
+// 所有的值都是字符串，不会推断数字或bool类型
+func NewJsonFromQuery(values url.Values) *GoJson {
+	flat := make(map[string]interface{}, len(values))
+	for key, vals := range values {
+		if len(vals) == 1 {
+			flat[key] = vals[0]
+			continue
+		}
+		list := make([]interface{}, 0, len(vals))
+		for _, val := range vals {
+			list = append(list, val)
+		}
+		flat[key] = list
+	}
+	return UnflattenWith(flat, ".")
+}